@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
+)
+
+// assignment is a row of the assign CSV file.
+type assignment struct {
+	serial string
+	user   string
+}
+
+func runAssign(args []string) error {
+	flags, format := newFlagSet("assign")
+	file := flags.String("csv", "", "CSV file with a serial_number and a user column, the user is an identifier or email")
+	dryRun := flags.Bool("dry-run", false, "look up the users without assigning the devices")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		flags.Usage()
+		return errors.New("-csv is required")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	assignments, err := readAssignments(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *file, err)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	results, failed := assign(c, assignments, *dryRun)
+	if err := writeRecords(os.Stdout, *format, []string{"serial_number", "user", "iduser", "status"}, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d assignments failed", failed, len(assignments))
+	}
+
+	return nil
+}
+
+// readAssignments reads the serial_number and user columns of a CSV file
+// with a header, other columns are ignored.
+func readAssignments(r io.Reader) ([]assignment, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+
+	columns := map[string]int{"serial_number": -1, "user": -1}
+	for i, name := range rows[0] {
+		if _, ok := columns[strings.TrimSpace(name)]; ok {
+			columns[strings.TrimSpace(name)] = i
+		}
+	}
+	for name, i := range columns {
+		if i < 0 {
+			return nil, fmt.Errorf("missing the %s column", name)
+		}
+	}
+
+	assignments := make([]assignment, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		a := assignment{
+			serial: strings.ToUpper(strings.TrimSpace(row[columns["serial_number"]])),
+			user:   strings.TrimSpace(row[columns["user"]]),
+		}
+		if a.serial == "" || a.user == "" {
+			return nil, fmt.Errorf("line %d: serial_number and user must be set", line+2)
+		}
+		assignments = append(assignments, a)
+	}
+
+	return assignments, nil
+}
+
+// assign looks up all users at once and assigns the devices one by one, a
+// failed assignment does not stop the others.
+func assign(c *provider.Client, assignments []assignment, dryRun bool) ([]map[string]interface{}, int) {
+	results := make([]map[string]interface{}, len(assignments))
+	failed := 0
+
+	idusers, err := lookupUsers(c, assignments)
+	for i, a := range assignments {
+		results[i] = map[string]interface{}{"serial_number": a.serial, "user": a.user}
+
+		var status error
+		iduser, ok := idusers[strings.ToLower(a.user)]
+		switch {
+		case err != nil:
+			status = err
+		case !ok:
+			status = errors.New("user not found")
+		case !dryRun:
+			status = c.AssignDevice(a.serial, iduser)
+		}
+
+		results[i]["iduser"] = iduser
+		if status != nil {
+			results[i]["status"] = "failed: " + status.Error()
+			failed++
+		} else if dryRun {
+			results[i]["status"] = "dry run"
+		} else {
+			results[i]["status"] = "assigned"
+		}
+	}
+
+	return results, failed
+}
+
+// lookupUsers maps the lower cased identifiers and emails of the assignments
+// onto Mosyle user ids.
+func lookupUsers(c *provider.Client, assignments []assignment) (map[string]string, error) {
+	identifiers, emails := make([]string, 0), make([]string, 0)
+	for _, a := range assignments {
+		if strings.Contains(a.user, "@") {
+			emails = append(emails, a.user)
+		} else {
+			identifiers = append(identifiers, a.user)
+		}
+	}
+
+	idusers := make(map[string]string)
+	for key, values := range map[string][]string{"identifiers": identifiers, "emails": emails} {
+		if len(values) == 0 {
+			continue
+		}
+
+		users, err := c.Users(map[string]interface{}{key: values})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			for _, attribute := range []string{"identifier", "email"} {
+				if value, ok := user[attribute].(string); ok && value != "" {
+					idusers[strings.ToLower(value)] = fmt.Sprint(user["iduser"])
+				}
+			}
+		}
+	}
+
+	return idusers, nil
+}
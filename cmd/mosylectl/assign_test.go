@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
+)
+
+func TestReadAssignments(t *testing.T) {
+	assignments, err := readAssignments(strings.NewReader("name,user,serial_number\nJane,jdoe, c02xk1jkjgh5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assignments) != 1 || assignments[0] != (assignment{serial: "C02XK1JKJGH5", user: "jdoe"}) {
+		t.Errorf("unexpected assignments %+v", assignments)
+	}
+
+	for _, file := range []string{"", "serial_number\nC02XK1JKJGH5\n", "serial_number,user\nC02XK1JKJGH5,\n"} {
+		if _, err := readAssignments(strings.NewReader(file)); err == nil {
+			t.Errorf("expected an error for %q", file)
+		}
+	}
+}
+
+func TestAssign(t *testing.T) {
+	assigned := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch body["operation"] {
+		case "list_users":
+			w.Write([]byte(`{"status":"OK","response":[{"users":[{"iduser":"1","identifier":"jdoe","email":"jane@example.com"}],"rows":1}]}`))
+		case "assign_device_user":
+			b, _ := json.Marshal(body["assign"])
+			assigned = append(assigned, string(b))
+			w.Write([]byte(`{"status":"OK"}`))
+		}
+	}))
+	defer server.Close()
+
+	c, _ := provider.MosyleClient("dev", nil, nil, nil)
+	c.HostURL = server.URL
+
+	results, failed := assign(c, []assignment{
+		{serial: "C02XK1JKJGH5", user: "jdoe"},
+		{serial: "DMPXK1JKJGH5", user: "Jane@example.com"},
+		{serial: "F9FXK1JKJGH5", user: "nobody"},
+	}, false)
+
+	if failed != 1 || results[2]["status"] != "failed: user not found" {
+		t.Errorf("expected the unknown user to fail, got %v", results)
+	}
+	if len(assigned) != 2 || assigned[1] != `[{"iduser":"1","serialnumber":"DMPXK1JKJGH5"}]` {
+		t.Errorf("unexpected assignments %v", assigned)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
+)
+
+func runDevices(args []string) error {
+	flags, format := newFlagSet("devices")
+	oses := flags.String("os", strings.Join(provider.DeviceOSes(), ","), "comma separated OSes to list")
+	serials := flags.String("serial", "", "comma separated serial numbers to list")
+	tags := flags.String("tag", "", "comma separated tags to list")
+	notSeen := flags.Int("not-seen", 0, "only list devices that have not checked in for this many days")
+	columns := flags.String("columns", "serial_number,os,device_name,device_model_name,username,date_last_beat", "comma separated device attributes to show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	options := make(map[string]interface{})
+	if list := splitList(*serials); list != nil {
+		options["serial_numbers"] = list
+	}
+	if list := splitList(*tags); list != nil {
+		options["tags"] = list
+	}
+
+	devices, warnings, err := c.Devices(splitList(*oses), options)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	if *notSeen > 0 {
+		devices = notSeenSince(devices, time.Now().AddDate(0, 0, -*notSeen))
+	}
+
+	return writeRecords(os.Stdout, *format, splitList(*columns), devices)
+}
+
+// notSeenSince keeps the devices whose last check in was before since,
+// including the ones that never checked in.
+func notSeenSince(devices []map[string]interface{}, since time.Time) []map[string]interface{} {
+	stale := make([]map[string]interface{}, 0)
+	for _, device := range devices {
+		beat, err := time.Parse(time.RFC3339, fmt.Sprint(device["date_last_beat"]))
+		if err != nil || beat.Before(since) {
+			stale = append(stale, device)
+		}
+	}
+
+	return stale
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
+)
+
+func runDoctor(args []string) error {
+	flags, format := newFlagSet("doctor")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Unlike the other commands doctor also runs with partial credentials, to
+	// find out what they give access to. MosyleClient rejects a username and
+	// password without an access token, so they are set on the client directly
+	// and the credentials check of doctor reports the missing token instead.
+	username, password, token := os.Getenv("MOSYLE_USERNAME"), os.Getenv("MOSYLE_PASSWORD"), os.Getenv("MOSYLE_TOKEN")
+	c, err := provider.MosyleClient(version, nil, nil, &token)
+	if err != nil {
+		return err
+	}
+	c.Auth.Username, c.Auth.Password = username, password
+
+	checks := c.Doctor()
+
+	records := make([]map[string]interface{}, len(checks))
+	failed := 0
+	for i, check := range checks {
+		status := "ok"
+		if !check.OK && check.Informational {
+			status = "info"
+		} else if !check.OK {
+			status = "fail"
+			failed++
+		}
+		records[i] = map[string]interface{}{"check": check.Name, "status": status, "detail": check.Detail}
+	}
+
+	if err := writeRecords(os.Stdout, *format, []string{"check", "status", "detail"}, records); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+
+	return nil
+}
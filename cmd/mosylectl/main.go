@@ -0,0 +1,107 @@
+// Command mosylectl looks up and assigns Mosyle devices and users without a
+// Terraform run. It uses the same client and credentials as the provider.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
+)
+
+var (
+	// set by the release build, like the provider version
+	version string = "dev"
+)
+
+// command is a mosylectl subcommand, run with the arguments after its name.
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+var commands []command
+
+// commands is filled in by init, the commands use newFlagSet which looks up
+// their usage in it.
+func init() {
+	commands = []command{
+		{"devices", "devices [flags]", "List devices", runDevices},
+		{"users", "users [flags]", "List users", runUsers},
+		{"search", "search [flags] TERM", "Find devices by serial number and users by identifier or email", runSearch},
+		{"assign", "assign [flags] -csv FILE", "Assign devices to users from a CSV file", runAssign},
+		{"doctor", "doctor [flags]", "Check DNS, TLS, authentication and the API endpoints the credentials can reach", runDoctor},
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != flag.Arg(0) {
+			continue
+		}
+
+		// Spans are only exported when OTEL_EXPORTER_OTLP_ENDPOINT is set,
+		// they are flushed before exiting.
+		shutdown, err := provider.StartTracing(context.Background(), "mosylectl", version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mosylectl: %s\n", err)
+			os.Exit(1)
+		}
+
+		err = cmd.run(flag.Args()[1:])
+		shutdown(context.Background())
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mosylectl %s: %s\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "mosylectl: unknown command %q\n\n", flag.Arg(0))
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: mosylectl COMMAND [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nCredentials are read from MOSYLE_USERNAME, MOSYLE_PASSWORD and MOSYLE_TOKEN.\nRun mosylectl COMMAND -h for the flags of a command.\n")
+}
+
+// newFlagSet creates the flags of a command, including the output format.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			flags.Usage = func() {
+				fmt.Fprintf(flags.Output(), "Usage: mosylectl %s\n\n%s\n\n", cmd.usage, cmd.description)
+				flags.PrintDefaults()
+			}
+		}
+	}
+
+	format := flags.String("o", "table", "output format, one of (table|json|csv)")
+
+	return flags, format
+}
+
+func newClient() (*provider.Client, error) {
+	return provider.MosyleClientFromEnv(version)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// writeRecords writes the given columns of records as a table, JSON or CSV.
+func writeRecords(w io.Writer, format string, columns []string, records []map[string]interface{}) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, record := range records {
+			fmt.Fprintln(tw, strings.Join(recordRow(columns, record), "\t"))
+		}
+		return tw.Flush()
+	case "json":
+		selected := make([]map[string]interface{}, len(records))
+		for i, record := range records {
+			selected[i] = make(map[string]interface{}, len(columns))
+			for _, column := range columns {
+				selected[i][column] = record[column]
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(selected)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(columns)
+		for _, record := range records {
+			cw.Write(recordRow(columns, record))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unknown output format %q, expected one of (table|json|csv)", format)
+}
+
+// recordRow formats the given columns of a record, lists such as tags are
+// joined with commas.
+func recordRow(columns []string, record map[string]interface{}) []string {
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		switch value := record[column].(type) {
+		case nil:
+		case []interface{}:
+			items := make([]string, len(value))
+			for j, item := range value {
+				items[j] = fmt.Sprint(item)
+			}
+			row[i] = strings.Join(items, ",")
+		default:
+			row[i] = fmt.Sprint(value)
+		}
+	}
+
+	return row
+}
+
+// splitList splits a comma separated flag value, an empty value is no list.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+
+	return items
+}
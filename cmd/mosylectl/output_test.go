@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteRecords(t *testing.T) {
+	records := []map[string]interface{}{
+		{"serial_number": "C02XK1JKJGH5", "tags": []interface{}{"lab", "loaner"}, "battery": 0.5},
+		{"serial_number": "DMPXK1JKJGH5"},
+	}
+	columns := []string{"serial_number", "tags", "battery"}
+
+	for format, expected := range map[string]string{
+		"table": "SERIAL_NUMBER  TAGS        BATTERY\nC02XK1JKJGH5   lab,loaner  0.5\nDMPXK1JKJGH5               \n",
+		"csv":   "serial_number,tags,battery\nC02XK1JKJGH5,\"lab,loaner\",0.5\nDMPXK1JKJGH5,,\n",
+		"json":  "[\n  {\n    \"battery\": 0.5,\n    \"serial_number\": \"C02XK1JKJGH5\",\n    \"tags\": [\n      \"lab\",\n      \"loaner\"\n    ]\n  },\n  {\n    \"battery\": null,\n    \"serial_number\": \"DMPXK1JKJGH5\",\n    \"tags\": null\n  }\n]\n",
+	} {
+		var b bytes.Buffer
+		if err := writeRecords(&b, format, columns, records); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", format, expected, b.String())
+		}
+	}
+
+	if err := writeRecords(&bytes.Buffer{}, "yaml", columns, records); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
+)
+
+// searchColumns are the columns of search results, a device row shows the
+// user it is assigned to.
+var searchColumns = []string{"kind", "serial_number", "os", "device_name", "identifier", "name", "email"}
+
+func runSearch(args []string) error {
+	flags, format := newFlagSet("search")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single search term")
+	}
+	term := strings.TrimSpace(flags.Arg(0))
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	results, err := search(c, term)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no device or user matches %q", term)
+	}
+
+	return writeRecords(os.Stdout, *format, searchColumns, results)
+}
+
+// search looks the term up as a serial number, identifier and email.
+func search(c *provider.Client, term string) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, 0)
+
+	devices, _, err := c.Devices(provider.DeviceOSes(), map[string]interface{}{
+		"serial_numbers":   []string{strings.ToUpper(term)},
+		"specific_columns": []string{"serial_number", "device_name", "idusermosyle"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idusers := make([]string, 0, len(devices))
+	for _, device := range devices {
+		if iduser, ok := device["idusermosyle"].(string); ok && iduser != "" {
+			idusers = append(idusers, iduser)
+		}
+	}
+	owners := make(map[string]map[string]interface{})
+	if len(idusers) > 0 {
+		users, err := c.Users(map[string]interface{}{"idusers": idusers})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			owners[fmt.Sprint(user["iduser"])] = user
+		}
+	}
+
+	for _, device := range devices {
+		result := map[string]interface{}{
+			"kind":          "device",
+			"serial_number": device["serial_number"],
+			"os":            device["os"],
+			"device_name":   device["device_name"],
+		}
+		if owner, ok := owners[fmt.Sprint(device["idusermosyle"])]; ok {
+			for _, key := range []string{"identifier", "name", "email"} {
+				result[key] = owner[key]
+			}
+		}
+		results = append(results, result)
+	}
+
+	lookups := map[string]interface{}{"identifiers": []string{term}}
+	if strings.Contains(term, "@") {
+		lookups = map[string]interface{}{"emails": []string{term}}
+	}
+	users, err := c.Users(lookups)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		results = append(results, map[string]interface{}{
+			"kind":       "user",
+			"identifier": user["identifier"],
+			"name":       user["name"],
+			"email":      user["email"],
+		})
+	}
+
+	return results, nil
+}
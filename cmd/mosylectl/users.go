@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+)
+
+func runUsers(args []string) error {
+	flags, format := newFlagSet("users")
+	identifiers := flags.String("identifier", "", "comma separated identifiers to list")
+	emails := flags.String("email", "", "comma separated emails to list")
+	userTypes := flags.String("type", "", "comma separated user types to list, each one of (ENDUSER|GROUP_ADMIN|ADMIN)")
+	columns := flags.String("columns", "identifier,name,email,type,iduser", "comma separated user attributes to show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	options := make(map[string]interface{})
+	for key, value := range map[string]string{
+		"identifiers": *identifiers,
+		"emails":      *emails,
+		"types":       *userTypes,
+	} {
+		if list := splitList(value); list != nil {
+			options[key] = list
+		}
+	}
+
+	users, err := c.Users(options)
+	if err != nil {
+		return err
+	}
+
+	return writeRecords(os.Stdout, *format, splitList(*columns), users)
+}
@@ -0,0 +1,6 @@
+# terraform apply -invoke=action.mosyle_move_to_limbo.returned
+action "mosyle_move_to_limbo" "returned" {
+  config {
+    device_udids = ["00008103-001A2B3C4D5E6F70"]
+  }
+}
\ No newline at end of file
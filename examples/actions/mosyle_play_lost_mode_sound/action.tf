@@ -0,0 +1,6 @@
+# terraform apply -invoke=action.mosyle_play_lost_mode_sound.lost
+action "mosyle_play_lost_mode_sound" "lost" {
+  config {
+    device_udids = ["00008103-001A2B3C4D5E6F70"]
+  }
+}
\ No newline at end of file
@@ -0,0 +1,11 @@
+data "mosyle_devices" "stale" {
+  os   = "ios"
+  tags = ["needs-inventory"]
+}
+
+# terraform apply -invoke=action.mosyle_refresh_inventory.stale
+action "mosyle_refresh_inventory" "stale" {
+  config {
+    device_udids = [for device in data.mosyle_devices.stale.devices : device.deviceudid]
+  }
+}
\ No newline at end of file
@@ -0,0 +1,19 @@
+action "mosyle_restart_device" "office" {
+  config {
+    device_udids = [mosyle_assignment.office.device_udid]
+  }
+}
+
+# Restart the device whenever it is assigned to someone else
+resource "mosyle_assignment" "office" {
+  os              = "mac"
+  device_serial   = "C02XK1JKJGH5"
+  user_identifier = "jdoe"
+
+  lifecycle {
+    action_trigger {
+      events  = [after_create, after_update]
+      actions = [action.mosyle_restart_device.office]
+    }
+  }
+}
\ No newline at end of file
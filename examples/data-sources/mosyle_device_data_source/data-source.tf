@@ -0,0 +1,8 @@
+data "mosyle_device" "laptop" {
+  serial_number = "JAYT56EFSR23"
+}
+
+data "mosyle_device" "ipad" {
+  deviceudid = "00008030-001A2C3E0C31802E"
+  os         = "ios"
+}
\ No newline at end of file
@@ -0,0 +1,11 @@
+data "mosyle_devicegroup" "lab" {
+  name = "Lab machines"
+}
+
+resource "mosyle_assignment" "lab" {
+  for_each = { for device in data.mosyle_devicegroup.lab.devices : device.serial_number => device }
+
+  os              = each.value.os
+  device_serial   = each.key
+  user_identifier = "lab"
+}
\ No newline at end of file
@@ -1,5 +1,26 @@
 data "mosyle_devices" "mac" {
-  filter = {
-    os = "mac"
-  }
+  os = "mac"
+}
+
+data "mosyle_devices" "tagged" {
+  os         = "ios"
+  tags       = ["classroom", "loaner"]
+  osversions = ["17.5", "17.6"]
+}
+
+data "mosyle_devices" "owners" {
+  os      = "mac"
+  columns = ["serial_number", "userid"]
+}
+
+locals {
+  serial_to_user = { for device in data.mosyle_devices.owners.devices : device.serial_number => device.userid }
+}
+
+data "mosyle_devices" "fleet" {
+  os = "all"
+}
+
+data "mosyle_devices" "mobile" {
+  operating_systems = ["ios", "tvos"]
 }
\ No newline at end of file
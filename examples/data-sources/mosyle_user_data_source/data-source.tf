@@ -0,0 +1,9 @@
+data "mosyle_user" "henk" {
+  identifier = "h.kar"
+}
+
+resource "mosyle_assignment" "henk_laptop" {
+  os            = "mac"
+  device_serial = "JAYT56EFSR23"
+  iduser        = data.mosyle_user.henk.iduser
+}
\ No newline at end of file
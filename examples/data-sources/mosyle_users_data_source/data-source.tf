@@ -1,7 +1,10 @@
 data "mosyle_users" "all" {}
 
+data "mosyle_users" "admins" {
+  types           = ["ADMIN", "GROUP_ADMIN"]
+  include_removed = false
+}
+
 data "mosyle_users" "some" {
-  filter = {
-    some = "val"
-  }
+  identifiers = ["h.kar", "j.doe"]
 }
\ No newline at end of file
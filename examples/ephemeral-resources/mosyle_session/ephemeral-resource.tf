@@ -0,0 +1,10 @@
+ephemeral "mosyle_session" "api" {}
+
+# Ephemeral values can only be passed to other ephemeral contexts, such as
+# provider configuration, write-only attributes or provisioner environments.
+provider "restapi" {
+  uri = "https://businessapi.mosyle.com/v1"
+  headers = {
+    Authorization = "Bearer ${ephemeral.mosyle_session.api.token}"
+  }
+}
\ No newline at end of file
@@ -0,0 +1,3 @@
+data "mosyle_devices" "inventory" {
+  operating_systems = [for os in ["macOS", "iPadOS"] : provider::mosyle::normalize_os(os)]
+}
\ No newline at end of file
@@ -0,0 +1,3 @@
+data "mosyle_device" "scanned" {
+  serial_number = provider::mosyle::normalize_serial(" c02-xk0aa-jgh5 ")
+}
\ No newline at end of file
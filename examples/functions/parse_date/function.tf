@@ -0,0 +1,3 @@
+output "enrolled_at" {
+  value = provider::mosyle::parse_date(data.mosyle_device.laptop.raw["date_enroll"])
+}
\ No newline at end of file
@@ -0,0 +1,3 @@
+output "tags" {
+  value = provider::mosyle::split_tags(data.mosyle_device.laptop.raw["tags"])
+}
\ No newline at end of file
@@ -0,0 +1,8 @@
+list "mosyle_assignment" "macs" {
+  provider = mosyle
+
+  config {
+    operating_systems = ["mac"]
+    tags              = ["classroom"]
+  }
+}
\ No newline at end of file
@@ -0,0 +1,7 @@
+list "mosyle_device_group" "labs" {
+  provider = mosyle
+
+  config {
+    names = ["Lab Macs", "Lab iPads"]
+  }
+}
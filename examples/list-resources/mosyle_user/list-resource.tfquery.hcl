@@ -0,0 +1,7 @@
+list "mosyle_user" "admins" {
+  provider = mosyle
+
+  config {
+    types = ["ADMIN", "GROUP_ADMIN"]
+  }
+}
\ No newline at end of file
@@ -0,0 +1,7 @@
+import {
+  to = mosyle_assignment.my_device
+  identity = {
+    os            = "mac"
+    device_serial = "JAYT56EFSR23"
+  }
+}
@@ -0,0 +1,4 @@
+import {
+  to = mosyle_assignment.my_device
+  id = "mac/JAYT56EFSR23"
+}
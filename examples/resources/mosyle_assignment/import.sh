@@ -0,0 +1,2 @@
+# Assignments are imported by the OS and serial number of the device
+terraform import mosyle_assignment.my_device mac/JAYT56EFSR23
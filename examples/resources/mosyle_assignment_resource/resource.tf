@@ -1,5 +1,5 @@
 resource "mosyle_assignment" "my_device" {
-  os            = "mac"
-  device_serial = "JAYT56EFSR23"
-  user_id       = "33334"
+  os              = "mac"
+  device_serial   = "JAYT56EFSR23"
+  user_identifier = "h.kar"
 }
\ No newline at end of file
@@ -0,0 +1,6 @@
+import {
+  to = mosyle_device_group.lab
+  identity = {
+    id = "12"
+  }
+}
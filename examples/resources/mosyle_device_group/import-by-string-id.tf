@@ -0,0 +1,4 @@
+import {
+  to = mosyle_device_group.lab
+  id = "12"
+}
@@ -0,0 +1,2 @@
+# Device groups are imported by their id
+terraform import mosyle_device_group.lab 12
@@ -0,0 +1,6 @@
+import {
+  to = mosyle_user.test
+  identity = {
+    identifier = "h.kar"
+  }
+}
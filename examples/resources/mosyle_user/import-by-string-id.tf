@@ -0,0 +1,4 @@
+import {
+  to = mosyle_user.test
+  id = "h.kar"
+}
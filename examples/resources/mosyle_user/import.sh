@@ -0,0 +1,2 @@
+# Users are imported by their identifier
+terraform import mosyle_user.test h.kar
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ action.ActionWithConfigure = &deviceAction{}
+
+// deviceAction implements an action that runs a single operation of the
+// devices endpoint for a list of devices.
+type deviceAction struct {
+	name        string
+	description string
+	operation   string
+	client      *Client
+}
+
+type deviceActionModel struct {
+	DeviceUDIDs types.List `tfsdk:"device_udids"`
+}
+
+func (a *deviceAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + a.name
+}
+
+func (a *deviceAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: a.description,
+		Attributes: map[string]schema.Attribute{
+			"device_udids": schema.ListAttribute{
+				MarkdownDescription: "UDIDs of the devices, such as the `device_udid` of a `mosyle_assignment`",
+				Required:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(notEmptyValidator),
+				},
+			},
+		},
+	}
+}
+
+func (a *deviceAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	c, ok := clientFromProviderData(req.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *Client, got %T", req.ProviderData))
+		return
+	}
+
+	a.client = c
+}
+
+func (a *deviceAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data deviceActionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	devices := stringValues(data.DeviceUDIDs)
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Running %s for %s", a.operation, strings.Join(devices, ", ")),
+	})
+
+	err := a.client.deviceOperation(ctx, a.operation, devices)
+	if err != nil {
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Running %s for %d device(s) failed", a.operation, len(devices)))
+	}
+}
+
+func NewRestartDeviceAction() action.Action {
+	return &deviceAction{
+		name:        "restart_device",
+		description: "Restarts devices",
+		operation:   "restart_devices",
+	}
+}
+
+func NewPlayLostModeSoundAction() action.Action {
+	return &deviceAction{
+		name:        "play_lost_mode_sound",
+		description: "Plays a sound on devices that are in lost mode, devices that are not in lost mode ignore it",
+		operation:   "play_sound",
+	}
+}
+
+func NewRefreshInventoryAction() action.Action {
+	return &deviceAction{
+		name:        "refresh_inventory",
+		description: "Asks devices to send their inventory, the new values show up in `mosyle_device` and `mosyle_devices` once the devices have checked in",
+		operation:   "update_inventory",
+	}
+}
+
+func NewMoveToLimboAction() action.Action {
+	return &deviceAction{
+		name:        "move_to_limbo",
+		description: "Moves devices to limbo, the same as destroying their `mosyle_assignment`",
+		operation:   "change_to_limbo",
+	}
+}
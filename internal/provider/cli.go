@@ -0,0 +1,50 @@
+package provider
+
+// The functions in this file expose the client to the command line tools in
+// cmd, records are decoded the same way the data sources decode them.
+
+import (
+	"context"
+)
+
+// DeviceOSes returns the operating systems devices can be listed for.
+func DeviceOSes() []string {
+	return append([]string{}, deviceOSes...)
+}
+
+// Devices lists the devices of the given OSes like the mosyle_devices data
+// source does. Values that could not be decoded are reported as warnings.
+func (c *Client) Devices(oses []string, options map[string]interface{}) ([]map[string]interface{}, []string, error) {
+	records, err := c.listDevicesByOS(context.Background(), oses, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ois, warnings := flattenDevices(records)
+	devices := make([]map[string]interface{}, len(ois))
+	for i, oi := range ois {
+		devices[i] = oi.(map[string]interface{})
+	}
+	sortRecords(devices, "serial_number")
+
+	return devices, warnings, nil
+}
+
+// Users lists users like the mosyle_users data source does.
+func (c *Client) Users(options map[string]interface{}) ([]map[string]interface{}, error) {
+	records, err := c.listUsers(context.Background(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	users := flattenUsers(records)
+	sortRecords(users, "identifier")
+
+	return users, nil
+}
+
+// AssignDevice assigns the device with the given serial number to the user
+// with the given Mosyle user id, like mosyle_assignment does.
+func (c *Client) AssignDevice(serial string, iduser string) error {
+	return assignDevice(context.Background(), c, serial, iduser)
+}
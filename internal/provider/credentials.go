@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// configProblem is a problem with the provider configuration, reported on
+// the attribute that has to change. Problems that are not caused by a single
+// attribute have no attribute.
+type configProblem struct {
+	attribute string
+	summary   string
+	detail    string
+}
+
+// credentialProblems checks the combination of credentials. The access token
+// is always needed, the username and password are optional but only together.
+func credentialProblems(username, password, accesstoken string) []configProblem {
+	problems := make([]configProblem, 0)
+
+	if accesstoken == "" {
+		problems = append(problems, configProblem{"accesstoken", "Missing Mosyle access token",
+			"Every call to the Mosyle API needs the access token of the API integration. Set accesstoken or the MOSYLE_TOKEN environment variable."})
+	}
+	if username != "" && password == "" {
+		problems = append(problems, configProblem{"password", "Missing Mosyle password",
+			"A username is configured without a password. Set password or the MOSYLE_PASSWORD environment variable, or neither username nor password to only use the access token."})
+	}
+	if password != "" && username == "" {
+		problems = append(problems, configProblem{"username", "Missing Mosyle username",
+			"A password is configured without a username. Set username or the MOSYLE_USERNAME environment variable, or neither username nor password to only use the access token."})
+	}
+
+	return problems
+}
+
+// checkCredentials validates the credentials of a client, and unless skip is
+// set makes one cheap call to check that Mosyle accepts them.
+func checkCredentials(ctx context.Context, c *Client, skip bool) []configProblem {
+	problems := credentialProblems(c.Auth.Username, c.Auth.Password, c.Auth.Token)
+	if len(problems) > 0 || skip {
+		return problems
+	}
+
+	err := probeCredentials(ctx, c)
+	if err == nil {
+		return problems
+	}
+
+	var url_err *url.Error
+	if errors.As(err, &url_err) {
+		return append(problems, configProblem{"", "Failed to reach Mosyle",
+			fmt.Sprintf("Checking the credentials failed: %s. Set skip_credentials_validation to configure the provider without this check.", err)})
+	}
+
+	// Without a username the access token is the only credential that can be wrong
+	attribute := "password"
+	if c.Auth.Username == "" {
+		attribute = "accesstoken"
+	}
+
+	return append(problems, configProblem{attribute, "Mosyle rejected the credentials",
+		fmt.Sprintf("Checking the credentials failed: %s. Check the username, password and access token, or set skip_credentials_validation to configure the provider without this check.", err)})
+}
+
+// probeCredentials lists a single page of users, the cheapest call that
+// needs valid credentials.
+func probeCredentials(ctx context.Context, c *Client) error {
+	_, err := c.listUsers(ctx, map[string]interface{}{"page": 1})
+
+	return err
+}
+
+// credentialProcessTimeout bounds how long the credential process may take,
+// it might wait for a vault agent or a password manager.
+const credentialProcessTimeout = time.Minute
+
+// resolveCredentials reads the credentials from password_file and
+// accesstoken_file, and fills in the ones that are still missing with the
+// output of credential_process. The username, password and access token
+// passed in already fell back to the environment.
+func resolveCredentials(ctx context.Context, username, password, accesstoken, password_file, accesstoken_file, process string) (string, string, string, []configProblem) {
+	problems := make([]configProblem, 0)
+
+	for _, file := range []struct {
+		attribute string
+		path      string
+		value     *string
+	}{
+		{"password_file", password_file, &password},
+		{"accesstoken_file", accesstoken_file, &accesstoken},
+	} {
+		if file.path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(file.path)
+		if err != nil {
+			problems = append(problems, configProblem{file.attribute, "Failed to read credentials file", err.Error()})
+			continue
+		}
+		*file.value = strings.TrimSpace(string(b))
+	}
+
+	if process == "" || (username != "" && password != "" && accesstoken != "") {
+		return username, password, accesstoken, problems
+	}
+
+	output, err := runCredentialProcess(ctx, process)
+	if err != nil {
+		return username, password, accesstoken, append(problems, configProblem{"credential_process", "Failed to run credential process", err.Error()})
+	}
+	for _, value := range []struct {
+		from string
+		to   *string
+	}{
+		{output.Username, &username},
+		{output.Password, &password},
+		{output.AccessToken, &accesstoken},
+	} {
+		if *value.to == "" {
+			*value.to = value.from
+		}
+	}
+
+	return username, password, accesstoken, problems
+}
+
+// credentialProcessOutput is the JSON a credential process prints.
+type credentialProcessOutput struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	AccessToken string `json:"accesstoken"`
+}
+
+// credentialProcessCacheTTL is how long the output of a credential process is
+// reused. Both muxed providers are configured within a moment of each other
+// and should not run the process twice, but rotated credentials have to be
+// read again by long-lived processes.
+const credentialProcessCacheTTL = time.Minute
+
+// cachedCredentialProcessOutput is the output of a credential process and
+// when it has to be read again.
+type cachedCredentialProcessOutput struct {
+	output  credentialProcessOutput
+	expires time.Time
+}
+
+// credentialProcesses caches the output of credential processes. Concurrent
+// runs of the same process share one run, the cache is not locked while the
+// process runs.
+var credentialProcesses = struct {
+	group   singleflight.Group
+	mu      sync.Mutex
+	outputs map[string]cachedCredentialProcessOutput
+}{outputs: make(map[string]cachedCredentialProcessOutput)}
+
+// runCredentialProcess returns the output of process, running it unless a
+// recent output is cached. Failed runs are not cached.
+func runCredentialProcess(ctx context.Context, process string) (credentialProcessOutput, error) {
+	credentialProcesses.mu.Lock()
+	cached, ok := credentialProcesses.outputs[process]
+	credentialProcesses.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.output, nil
+	}
+
+	output, err, _ := credentialProcesses.group.Do(process, func() (interface{}, error) {
+		output, err := execCredentialProcess(ctx, process)
+		if err != nil {
+			return nil, err
+		}
+
+		credentialProcesses.mu.Lock()
+		credentialProcesses.outputs[process] = cachedCredentialProcessOutput{output, time.Now().Add(credentialProcessCacheTTL)}
+		credentialProcesses.mu.Unlock()
+
+		return output, nil
+	})
+	if err != nil {
+		return credentialProcessOutput{}, err
+	}
+
+	return output.(credentialProcessOutput), nil
+}
+
+// execCredentialProcess runs a command with the shell and decodes its output.
+func execCredentialProcess(ctx context.Context, process string) (credentialProcessOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialProcessTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", process)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", process)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	b, err := cmd.Output()
+	if err != nil {
+		return credentialProcessOutput{}, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := credentialProcessOutput{}
+	if err := json.Unmarshal(b, &output); err != nil {
+		return credentialProcessOutput{}, fmt.Errorf("decoding the output: %w", err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCredentialProblems(t *testing.T) {
+	for name, tc := range map[string]struct {
+		username, password, accesstoken string
+		attributes                      []string
+	}{
+		"all":           {"admin@example.com", "secret", "token", nil},
+		"token only":    {"", "", "token", nil},
+		"nothing":       {"", "", "", []string{"accesstoken"}},
+		"no password":   {"admin@example.com", "", "token", []string{"password"}},
+		"no username":   {"", "secret", "token", []string{"username"}},
+		"no token":      {"admin@example.com", "secret", "", []string{"accesstoken"}},
+		"only username": {"admin@example.com", "", "", []string{"accesstoken", "password"}},
+	} {
+		problems := credentialProblems(tc.username, tc.password, tc.accesstoken)
+		if len(problems) != len(tc.attributes) {
+			t.Errorf("%s: expected %d problems, got %+v", name, len(tc.attributes), problems)
+			continue
+		}
+		for i, problem := range problems {
+			if problem.attribute != tc.attributes[i] {
+				t.Errorf("%s: expected a problem with %s, got %+v", name, tc.attributes[i], problem)
+			}
+		}
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("accesstoken") != "token" || r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":"OK","response":[{"users":[],"rows":0}]}`))
+	}))
+	defer server.Close()
+
+	token := "token"
+	c, _ := MosyleClient("dev", nil, nil, &token)
+	c.HostURL = server.URL
+
+	if problems := checkCredentials(context.Background(), c, false); len(problems) != 0 || calls != 1 {
+		t.Errorf("expected token only credentials to pass with one call, got %+v after %d calls", problems, calls)
+	}
+
+	c.Auth.Token = "wrong"
+	problems := checkCredentials(context.Background(), c, false)
+	if len(problems) != 1 || problems[0].attribute != "accesstoken" {
+		t.Errorf("expected the access token to be rejected, got %+v", problems)
+	}
+
+	if problems := checkCredentials(context.Background(), c, true); len(problems) != 0 || calls != 2 {
+		t.Errorf("expected skipping to make no call, got %+v after %d calls", problems, calls)
+	}
+}
+
+func TestResolveCredentials(t *testing.T) {
+	dir := t.TempDir()
+	password_file := filepath.Join(dir, "password")
+	os.WriteFile(password_file, []byte("from-file\n"), 0o600)
+	process := `echo '{"username": "process@example.com", "password": "from-process", "accesstoken": "process-token"}'`
+
+	username, password, accesstoken, problems := resolveCredentials(context.Background(), "admin@example.com", "", "", password_file, "", process)
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems %+v", problems)
+	}
+	if username != "admin@example.com" || password != "from-file" || accesstoken != "process-token" {
+		t.Errorf("expected configured values and files to take precedence over the process, got %q, %q, %q", username, password, accesstoken)
+	}
+
+	_, _, _, problems = resolveCredentials(context.Background(), "", "", "", filepath.Join(dir, "missing"), "", "exit 3")
+	if len(problems) != 2 || problems[0].attribute != "password_file" || problems[1].attribute != "credential_process" {
+		t.Errorf("expected problems with password_file and credential_process, got %+v", problems)
+	}
+}
+
+func TestRunCredentialProcessCache(t *testing.T) {
+	count := filepath.Join(t.TempDir(), "count")
+	process := fmt.Sprintf(`sleep 0.1; echo run >> %q; echo '{"accesstoken": "token"}'`, count)
+	runs := func() int {
+		b, _ := os.ReadFile(count)
+		return strings.Count(string(b), "run")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if output, err := runCredentialProcess(context.Background(), process); err != nil || output.AccessToken != "token" {
+				t.Errorf("unexpected output %+v, %v", output, err)
+			}
+		}()
+	}
+	wg.Wait()
+	runCredentialProcess(context.Background(), process)
+	if runs() != 1 {
+		t.Errorf("expected concurrent and cached runs to share one run, got %d", runs())
+	}
+
+	// Once the output expires the process runs again, rotated credentials are read
+	credentialProcesses.mu.Lock()
+	cached := credentialProcesses.outputs[process]
+	cached.expires = time.Now().Add(-time.Second)
+	credentialProcesses.outputs[process] = cached
+	credentialProcesses.mu.Unlock()
+
+	runCredentialProcess(context.Background(), process)
+	if runs() != 2 {
+		t.Errorf("expected an expired output to run the process again, got %d runs", runs())
+	}
+}
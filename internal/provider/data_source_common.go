@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// queryID derives a data source ID from the query it ran, so the ID only
+// changes when the query does. Lists of strings are sorted first as their
+// order does not change the result.
+func queryID(query map[string]interface{}) string {
+	// encoding/json writes map keys in sorted order
+	b, _ := json.Marshal(normaliseQuery(query))
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func normaliseQuery(value interface{}) interface{} {
+	switch values := value.(type) {
+	case map[string]interface{}:
+		normalised := make(map[string]interface{}, len(values))
+		for key, v := range values {
+			normalised[key] = normaliseQuery(v)
+		}
+		return normalised
+	case []interface{}:
+		strs := make([]string, len(values))
+		for i, v := range values {
+			strs[i] = fmt.Sprint(v)
+		}
+		sort.Strings(strs)
+		return strs
+	case []string:
+		strs := append([]string{}, values...)
+		sort.Strings(strs)
+		return strs
+	}
+
+	return value
+}
+
+// sortRecords orders flattened records by the value of key. Values that are
+// both numbers are compared numerically, everything else as strings.
+func sortRecords[T any](records []T, key string) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return lessValue(recordValue(records[i], key), recordValue(records[j], key))
+	})
+}
+
+func recordValue(record interface{}, key string) interface{} {
+	if r, ok := record.(map[string]interface{}); ok {
+		return r[key]
+	}
+
+	return nil
+}
+
+func lessValue(a, b interface{}) bool {
+	as := fmt.Sprint(a)
+	bs := fmt.Sprint(b)
+	if a == nil {
+		as = ""
+	}
+	if b == nil {
+		bs = ""
+	}
+
+	af, aerr := strconv.ParseFloat(as, 64)
+	bf, berr := strconv.ParseFloat(bs, 64)
+	if aerr == nil && berr == nil {
+		return af < bf
+	}
+
+	return as < bs
+}
+
+// schemaKeys lists the attribute names of a schema map in sorted order.
+func schemaKeys[T any](s map[string]T) []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
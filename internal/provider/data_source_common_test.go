@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestQueryID(t *testing.T) {
+	a := queryID(map[string]interface{}{"os": "mac", "options": map[string]interface{}{"tags": []interface{}{"b", "a"}}})
+	b := queryID(map[string]interface{}{"options": map[string]interface{}{"tags": []string{"a", "b"}}, "os": "mac"})
+	if a != b {
+		t.Fatalf("expected equal IDs for equivalent queries, got %s and %s", a, b)
+	}
+
+	c := queryID(map[string]interface{}{"os": "ios", "options": map[string]interface{}{"tags": []interface{}{"a", "b"}}})
+	if a == c {
+		t.Fatalf("expected different IDs for different queries, got %s", a)
+	}
+}
+
+func TestSortRecords(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"id": "10"},
+		map[string]interface{}{"id": "9"},
+		map[string]interface{}{"id": nil},
+	}
+	sortRecords(records, "id")
+
+	for i, want := range []interface{}{nil, "9", "10"} {
+		if got := records[i].(map[string]interface{})["id"]; got != want {
+			t.Fatalf("record %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDevice() *schema.Resource {
+	s := deviceSchema()
+	s["serial_number"] = &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ExactlyOneOf:     []string{"serial_number", "deviceudid"},
+		ValidateDiagFunc: validateSerialNumber,
+		Description:      "Serial number of the device to look up",
+	}
+	s["deviceudid"] = &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ExactlyOneOf:     []string{"serial_number", "deviceudid"},
+		ValidateDiagFunc: validateNotEmpty,
+		Description:      "UDID of the device to look up",
+	}
+	s["os"] = &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ValidateDiagFunc: validateOS,
+		Description:      "Device OS, one of " + deviceOSList() + ". All of them are searched when omitted",
+	}
+
+	return &schema.Resource{
+		Description: "A single device from Mosyle, looked up by serial number or UDID",
+		ReadContext: dataSourceDeviceRead,
+		Schema:      s,
+	}
+}
+
+func dataSourceDeviceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	serial := d.Get("serial_number").(string)
+	udid := d.Get("deviceudid").(string)
+
+	oses := deviceOSes
+	if os, ok := d.GetOk("os"); ok {
+		oses = []string{os.(string)}
+	}
+
+	options := make(map[string]interface{})
+	if serial != "" {
+		options["serial_numbers"] = []string{serial}
+	}
+
+	devices, err := c.listDevicesByOS(ctx, oses, options)
+	if err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  err.Error(),
+			Detail:   fmt.Sprintf("Listing %s devices failed", strings.Join(oses, ", ")),
+		})
+	}
+
+	matches := make([]map[string]interface{}, 0)
+	for _, device := range devices {
+		if serial != "" && strings.EqualFold(fmt.Sprint(device["serial_number"]), serial) {
+			matches = append(matches, device)
+		}
+		if udid != "" && strings.EqualFold(fmt.Sprint(device["deviceudid"]), udid) {
+			matches = append(matches, device)
+		}
+	}
+
+	lookup := fmt.Sprintf("serial number %q", serial)
+	if udid != "" {
+		lookup = fmt.Sprintf("UDID %q", udid)
+	}
+
+	if len(matches) < 1 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "No such device",
+			Detail:   fmt.Sprintf("No %s device matches %s", strings.Join(oses, ", "), lookup),
+		})
+	}
+	if len(matches) > 1 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Ambiguous device lookup",
+			Detail:   fmt.Sprintf("%d devices match %s, set os to narrow down the search", len(matches), lookup),
+		})
+	}
+
+	device, warnings := flattenDevice(matches[0])
+	diags = append(diags, decodeWarnings(warnings)...)
+
+	for key, val := range device {
+		if err := d.Set(key, val); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Failed to transfer data",
+				Detail:   err.Error(),
+			})
+		}
+	}
+
+	d.SetId(fmt.Sprint(matches[0]["deviceudid"]))
+
+	return diags
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDeviceGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: "A single device group from Mosyle, including its member devices",
+		ReadContext: dataSourceDeviceGroupRead,
+		Schema: map[string]*schema.Schema{
+			"id": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "Device group id",
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "Device group name",
+			},
+			"device_numbers": &schema.Schema{Type: schema.TypeInt, Computed: true, Description: "Number of devices in the group"},
+			"devices": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Devices in the group",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"serial_number": &schema.Schema{Type: schema.TypeString, Computed: true},
+						"deviceudid":    &schema.Schema{Type: schema.TypeString, Computed: true},
+						"os":            &schema.Schema{Type: schema.TypeString, Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDeviceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	key := "name"
+	if _, ok := d.GetOk("id"); ok {
+		key = "id"
+	}
+	value := d.Get(key).(string)
+
+	groups, err := c.listDeviceGroups(ctx, map[string]interface{}{})
+	if err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  err.Error(),
+			Detail:   "Listing device groups failed",
+		})
+	}
+
+	matches := make([]map[string]interface{}, 0)
+	for _, group := range flattenDeviceGroups(DeviceGroupListResponse{Response: ListResult{DeviceGroups: groups}}) {
+		oi := group.(map[string]interface{})
+		if oi[key] == value {
+			matches = append(matches, oi)
+		}
+	}
+
+	if len(matches) < 1 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "No such device group",
+			Detail:   fmt.Sprintf("No device group matches %s %q", key, value),
+		})
+	}
+	if len(matches) > 1 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Ambiguous device group lookup",
+			Detail:   fmt.Sprintf("%d device groups match %s %q, look it up by id instead", len(matches), key, value),
+		})
+	}
+
+	group := matches[0]
+	id, _ := group["id"].(string)
+
+	devices, err := c.listDeviceGroupDevices(ctx, id)
+	if err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  err.Error(),
+			Detail:   fmt.Sprintf("Listing devices of device group %s failed", id),
+		})
+	}
+
+	for _, key := range []string{"name", "device_numbers"} {
+		if err := d.Set(key, group[key]); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Failed to transfer data",
+				Detail:   err.Error(),
+			})
+		}
+	}
+
+	members := flattenDeviceGroupDevices(devices)
+	sortRecords(members, "serial_number")
+
+	if err := d.Set("devices", members); err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Failed to transfer data",
+			Detail:   err.Error(),
+		})
+	}
+
+	d.SetId(id)
+
+	return diags
+}
+
+func flattenDeviceGroupDevices(devices []map[string]interface{}) []interface{} {
+	ois := make([]interface{}, len(devices), len(devices))
+
+	for i, device := range devices {
+		oi := make(map[string]interface{})
+		for _, key := range []string{"serial_number", "deviceudid", "os"} {
+			if val, ok := device[key]; ok && val != nil {
+				if sval, err := coerceString(val); err == nil {
+					oi[key] = sval
+				}
+			}
+		}
+
+		ois[i] = oi
+	}
+
+	return ois
+}
@@ -7,13 +7,19 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func dataSourceDeviceGroups() *schema.Resource {
+	group := map[string]*schema.Schema{
+		"id":             &schema.Schema{Type: schema.TypeString, Computed: true},
+		"name":           &schema.Schema{Type: schema.TypeString, Computed: true},
+		"device_numbers": &schema.Schema{Type: schema.TypeInt, Computed: true},
+	}
+
 	return &schema.Resource{
 		ReadContext: dataSourceDeviceGroupsRead,
 		Schema: map[string]*schema.Schema{
@@ -25,16 +31,19 @@ func dataSourceDeviceGroups() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"sort_by": {
+				Type:             schema.TypeString,
+				Description:      "Device group attribute to sort the results by. Defaults to `id`",
+				Optional:         true,
+				Default:          "id",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(schemaKeys(group), false)),
+			},
 			"groups": &schema.Schema{
 				Type:        schema.TypeList,
 				Computed:    true,
 				Description: "Device group data from Mosyle",
 				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"id":             &schema.Schema{Type: schema.TypeString, Computed: true},
-						"name":           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"device_numbers": &schema.Schema{Type: schema.TypeInt, Computed: true},
-					},
+					Schema: group,
 				},
 			},
 		},
@@ -61,7 +70,7 @@ func dataSourceDeviceGroupsRead(ctx context.Context, d *schema.ResourceData, m i
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/devicegroups", c.HostURL), strings.NewReader(string(req_body)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/devicegroups", c.HostURL), strings.NewReader(string(req_body)))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -93,7 +102,10 @@ func dataSourceDeviceGroupsRead(ctx context.Context, d *schema.ResourceData, m i
 		})
 	}
 
-	if err := d.Set("groups", flattenDeviceGroups(response)); err != nil {
+	groups := flattenDeviceGroups(response)
+	sortRecords(groups, d.Get("sort_by").(string))
+
+	if err := d.Set("groups", groups); err != nil {
 		return append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Failed to transfer data",
@@ -101,8 +113,7 @@ func dataSourceDeviceGroupsRead(ctx context.Context, d *schema.ResourceData, m i
 		})
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(queryID(map[string]interface{}{"options": options, "sort_by": d.Get("sort_by")}))
 
 	return diags
 }
@@ -118,12 +129,21 @@ func flattenDeviceGroups(response DeviceGroupListResponse) []interface{} {
 		oi := make(map[string]interface{})
 
 		for key, val := range device {
-			if key == "device_numbers" {
-				ival, err := strconv.ParseInt(val.(string), 10, 64)
-				if err == nil {
-					oi[strings.ToLower(key)] = ival
+			switch key {
+			case "id", "name":
+				if sval, err := coerceString(val); err == nil {
+					oi[key] = sval
 					continue
 				}
+			case "device_numbers":
+				// The count is sent as a string or as a number
+				sval, err := coerceString(val)
+				if err == nil {
+					if ival, err := strconv.ParseInt(sval, 10, 64); err == nil {
+						oi[key] = ival
+						continue
+					}
+				}
 			}
 
 			oi[strings.ToLower(key)] = val
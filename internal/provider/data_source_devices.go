@@ -4,205 +4,226 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"strconv"
-	"strings"
-	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func dataSourceDevices() *schema.Resource {
-	return &schema.Resource{
-		ReadContext: dataSourceDevicesRead,
-		Schema: map[string]*schema.Schema{
-			"filter": {
-				Type:        schema.TypeMap,
-				Description: "Filters to limit API data",
-				Required:    true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+var _ datasource.DataSourceWithConfigure = &devicesDataSource{}
+
+func NewDevicesDataSource() datasource.DataSource {
+	return &devicesDataSource{}
+}
+
+type devicesDataSource struct {
+	client *Client
+}
+
+type devicesDataSourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	OS               types.String `tfsdk:"os"`
+	OperatingSystems types.List   `tfsdk:"operating_systems"`
+	SerialNumbers    types.List   `tfsdk:"serial_numbers"`
+	Tags             types.List   `tfsdk:"tags"`
+	OSVersions       types.List   `tfsdk:"osversions"`
+	Columns          types.List   `tfsdk:"columns"`
+	SortBy           types.String `tfsdk:"sort_by"`
+	Filter           types.Map    `tfsdk:"filter"`
+	Devices          types.List   `tfsdk:"devices"`
+}
+
+// deviceObjectTypes is the framework type of a single device, see deviceSchema.
+var deviceObjectTypes = attrTypes(deviceAttributes)
+
+func (d *devicesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_devices"
+}
+
+func (d *devicesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The ID of this resource.",
+			},
+			"os": schema.StringAttribute{
+				MarkdownDescription: "Device OS to list, one of " + deviceOSList("all"),
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("operating_systems"), path.MatchRoot("filter")),
+					stringvalidator.OneOf(append([]string{"all"}, deviceOSes...)...),
+				},
+			},
+			"operating_systems": schema.ListAttribute{
+				MarkdownDescription: "Device OSes to list, each one of " + deviceOSList(),
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(osValidator),
 				},
 			},
-			"devices": &schema.Schema{
-				Type:        schema.TypeList,
-				Computed:    true,
-				Description: "Device data from Mosyle, this can be macOS, iOS or tvOS",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"deviceudid":                       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"total_disk":                       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"os":                               &schema.Schema{Type: schema.TypeString, Computed: true},
-						"serial_number":                    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"device_model_name":                &schema.Schema{Type: schema.TypeString, Computed: true},
-						"device_name":                      &schema.Schema{Type: schema.TypeString, Computed: true},
-						"device_model":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"battery":                          &schema.Schema{Type: schema.TypeString, Computed: true},
-						"osversion":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"vpn_status":                       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"userid":                           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_info":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"carrier":                          &schema.Schema{Type: schema.TypeString, Computed: true},
-						"roaming_enabled":                  &schema.Schema{Type: schema.TypeString, Computed: true},
-						"isroaming":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"imei":                             &schema.Schema{Type: schema.TypeString, Computed: true},
-						"meid":                             &schema.Schema{Type: schema.TypeString, Computed: true},
-						"available_disk":                   &schema.Schema{Type: schema.TypeString, Computed: true},
-						"wifi_mac_address":                 &schema.Schema{Type: schema.TypeString, Computed: true},
-						"bluetooth_mac_address":            &schema.Schema{Type: schema.TypeString, Computed: true},
-						"is_supervised":                    &schema.Schema{Type: schema.TypeBool, Computed: true},
-						"date_app_info":                    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_last_beat":                   &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_last_push":                   &schema.Schema{Type: schema.TypeString, Computed: true},
-						"status":                           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"isactivationlockenabled":          &schema.Schema{Type: schema.TypeString, Computed: true},
-						"isdevicelocatorserviceenabled":    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"isdonotdisturbineffect":           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"iscloudbackupenabled":             &schema.Schema{Type: schema.TypeString, Computed: true},
-						"isnetworktethered":                &schema.Schema{Type: schema.TypeString, Computed: true},
-						"needosupdate":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"productkeyupdate":                 &schema.Schema{Type: schema.TypeString, Computed: true},
-						"device_type":                      &schema.Schema{Type: schema.TypeString, Computed: true},
-						"lostmode_status":                  &schema.Schema{Type: schema.TypeString, Computed: true},
-						"is_muted":                         &schema.Schema{Type: schema.TypeBool, Computed: true},
-						"date_muted":                       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"activation_bypass":                &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_media_info":                  &schema.Schema{Type: schema.TypeString, Computed: true},
-						"tags":                             &schema.Schema{Type: schema.TypeString, Computed: true},
-						"is_deleted":                       &schema.Schema{Type: schema.TypeBool, Computed: true},
-						"itunesstoreaccounthash":           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"itunesstoreaccountisactive":       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_profiles_info":               &schema.Schema{Type: schema.TypeString, Computed: true},
-						"ethernet_mac_address":             &schema.Schema{Type: schema.TypeString, Computed: true},
-						"model_name":                       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"lastcloudbackupdate":              &schema.Schema{Type: schema.TypeString, Computed: true},
-						"systemintegrityprotectionenabled": &schema.Schema{Type: schema.TypeString, Computed: true},
-						"buildversion":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"localhostname":                    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"hostname":                         &schema.Schema{Type: schema.TypeString, Computed: true},
-						"osupdatesettings":                 &schema.Schema{Type: schema.TypeString, Computed: true},
-						"activemanagedusers":               &schema.Schema{Type: schema.TypeString, Computed: true},
-						"currentconsolemanageduser":        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_printers":                    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"autosetupadminaccounts":           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"appletvid":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"asset_tag":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"managementstatus":                 &schema.Schema{Type: schema.TypeString, Computed: true},
-						"osupdatestatus":                   &schema.Schema{Type: schema.TypeString, Computed: true},
-						"availableosupdates":               &schema.Schema{Type: schema.TypeString, Computed: true},
-						"has_password":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"timezone":                         &schema.Schema{Type: schema.TypeString, Computed: true},
-						"activation_bypass_mdm":            &schema.Schema{Type: schema.TypeString, Computed: true},
-						"percent_disk":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"idsharedgroup":                    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"enrollment_type":                  &schema.Schema{Type: schema.TypeString, Computed: true},
-						"status_login":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_lastlogin":                   &schema.Schema{Type: schema.TypeString, Computed: true},
-						"idaccount":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_checkin":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_enroll":                      &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_checkout":                    &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_kinfo":                       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"cpu_model":                        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"hasvpn":                           &schema.Schema{Type: schema.TypeString, Computed: true},
-						"installed_memory":                 &schema.Schema{Type: schema.TypeString, Computed: true},
-						"username":                         &schema.Schema{Type: schema.TypeString, Computed: true},
-						"usertype":                         &schema.Schema{Type: schema.TypeString, Computed: true},
-						"idusermosyle":                     &schema.Schema{Type: schema.TypeString, Computed: true},
-					},
+			"serial_numbers": schema.ListAttribute{
+				MarkdownDescription: "Only list devices with these serial numbers",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.ConflictsWith(path.MatchRoot("filter")),
+					listvalidator.ValueStringsAre(serialNumberValidator),
 				},
 			},
+			"tags": schema.ListAttribute{
+				MarkdownDescription: "Only list devices with these tags",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.ConflictsWith(path.MatchRoot("filter")),
+					listvalidator.ValueStringsAre(notEmptyValidator),
+				},
+			},
+			"osversions": schema.ListAttribute{
+				MarkdownDescription: "Only list devices running these OS versions",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.ConflictsWith(path.MatchRoot("filter")),
+					listvalidator.ValueStringsAre(notEmptyValidator),
+				},
+			},
+			"columns": schema.ListAttribute{
+				MarkdownDescription: "Only fetch these device attributes, all of them are fetched when omitted",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.ConflictsWith(path.MatchRoot("filter")),
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(deviceColumns()...)),
+				},
+			},
+			"sort_by": schema.StringAttribute{
+				MarkdownDescription: "Device attribute to sort the results by. Defaults to `serial_number`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(deviceColumns()...),
+				},
+			},
+			"filter": schema.MapAttribute{
+				MarkdownDescription: "Filters to limit API data",
+				Optional:            true,
+				ElementType:         types.StringType,
+				DeprecationMessage:  "Use the os, serial_numbers, tags and osversions attributes instead",
+			},
+			"devices": schema.ListAttribute{
+				MarkdownDescription: "Device data from Mosyle, this can be macOS, iOS or tvOS",
+				Computed:            true,
+				ElementType:         types.ObjectType{AttrTypes: deviceObjectTypes},
+			},
 		},
 	}
 }
 
-func dataSourceDevicesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Client)
-
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
-
-	filter := d.Get("filter").(map[string]interface{})
-	options := make(map[string]interface{})
-	for key, value := range filter {
-		options[key] = value
+func (d *devicesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	c, ok := clientFromProviderData(req.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *Client, got %T", req.ProviderData))
+		return
 	}
 
-	req_data := ListPostBody{
-		Operation: "list",
-		Options:   options,
-	}
-	req_body, err := json.Marshal(req_data)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/devices", c.HostURL), strings.NewReader(string(req_body)))
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	d.client = c
+}
 
-	response, err := c.doRequest(req)
-	if err != nil {
-		return append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  err.Error(),
-			Detail:   fmt.Sprint(string(req_body)),
-		})
+func (d *devicesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data devicesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if err := d.Set("devices", flattenDevices(response)); err != nil {
-		return append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Failed to transfer data",
-			Detail:   err.Error(),
-		})
+	// Data sources cannot have a default, state holds it like the SDK did
+	if data.SortBy.IsNull() {
+		data.SortBy = types.StringValue("serial_number")
 	}
+	sortBy := data.SortBy.ValueString()
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
-
-	return diags
-}
-
-func flattenDevices(response ListResponse) []interface{} {
-	if len(response.Response) < 1 || len(response.Response[0].Devices) < 1 {
-		return make([]interface{}, 0)
-	}
+	options := make(map[string]interface{})
+	query := map[string]interface{}{"options": options, "sort_by": sortBy}
+	columns := stringValues(data.Columns)
+
+	var devices []map[string]interface{}
+	var err error
+	if !data.Filter.IsNull() {
+		for key, value := range data.Filter.Elements() {
+			options[key] = value.(types.String).ValueString()
+		}
 
-	ois := make([]interface{}, len(response.Response[0].Devices), len(response.Response[0].Devices))
-
-	for i, device := range response.Response[0].Devices {
-		oi := make(map[string]interface{})
-
-		for key, val := range device {
-			if strings.HasPrefix(key, "is_") {
-				if val == nil {
-					oi[strings.ToLower(key)] = false
-					continue
-				}
-				bval, err := strconv.ParseBool(val.(string))
-				if err == nil {
-					oi[strings.ToLower(key)] = bval
-					continue
-				}
+		devices, err = d.client.listDevices(ctx, options)
+	} else {
+		for key, values := range map[string]types.List{
+			"serial_numbers": data.SerialNumbers,
+			"tags":           data.Tags,
+			"osversions":     data.OSVersions,
+		} {
+			if len(values.Elements()) > 0 {
+				options[key] = stringValues(values)
 			}
+		}
+		if len(columns) > 0 {
+			options["specific_columns"] = columns
+			query["columns"] = columns
+		}
 
-			if strings.HasPrefix(key, "date_") && val != nil {
-				ival, err := strconv.ParseInt(val.(string), 10, 64)
-				if err == nil {
-					t := time.Unix(ival, 0)
-					strDate := t.Format(time.RFC3339)
-					oi[strings.ToLower(key)] = strDate
-					continue
-				}
-			}
+		oses := make([]string, 0)
+		switch os := data.OS.ValueString(); os {
+		case "all":
+			oses = deviceOSes
+		case "":
+			oses = stringValues(data.OperatingSystems)
+		default:
+			oses = append(oses, os)
+		}
+		query["os"] = oses
+
+		devices, err = d.client.listDevicesByOS(ctx, oses, options)
+	}
+	if err != nil {
+		req_body, _ := json.Marshal(query)
+		resp.Diagnostics.AddError(err.Error(), string(req_body))
+		return
+	}
 
-			oi[strings.ToLower(key)] = val
+	ois, warnings := flattenDevices(devices)
+	for _, warning := range warnings {
+		resp.Diagnostics.AddWarning("Unexpected device attribute value", warning)
+	}
+	if len(columns) > 0 {
+		ois = selectColumns(ois, columns)
+	}
+	sortRecords(ois, sortBy)
+
+	objects := make([]attr.Value, len(ois))
+	for i, oi := range ois {
+		object, diags := objectValue(deviceObjectTypes, oi.(map[string]interface{}))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		objects[i] = object
+	}
 
-		ois[i] = oi
+	list, diags := types.ListValue(types.ObjectType{AttrTypes: deviceObjectTypes}, objects)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return ois
+	data.Devices = list
+	data.ID = types.StringValue(queryID(query))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
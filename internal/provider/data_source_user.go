@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// userLookups maps the attributes that can identify a single user onto the
+// matching "list_users" option.
+var userLookups = map[string]string{
+	"identifier": "identifiers",
+	"email":      "emails",
+	"iduser":     "idusers",
+}
+
+func dataSourceUser() *schema.Resource {
+	lookup := []string{"identifier", "email", "iduser"}
+
+	return &schema.Resource{
+		Description: "A single user from Mosyle, looked up by identifier, email or Mosyle user id",
+		ReadContext: dataSourceUserRead,
+		Schema: map[string]*schema.Schema{
+			"identifier": &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true, ExactlyOneOf: lookup, ValidateDiagFunc: validateNotEmpty, Description: "User identifier, set by admin"},
+			"email":      &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true, ExactlyOneOf: lookup, ValidateDiagFunc: validateEmail, Description: "User email"},
+			"iduser":     &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true, ExactlyOneOf: lookup, ValidateDiagFunc: validateNotEmpty, Description: "User id from mosyle"},
+			"code":       &schema.Schema{Type: schema.TypeString, Computed: true, Description: "User code"},
+			"name":       &schema.Schema{Type: schema.TypeString, Computed: true, Description: "User name"},
+			"type":       &schema.Schema{Type: schema.TypeString, Computed: true, Description: "User type"},
+			"is_removed": &schema.Schema{Type: schema.TypeBool, Computed: true, Description: "User is removed"},
+		},
+	}
+}
+
+func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	var key, value string
+	for attribute := range userLookups {
+		if v, ok := d.GetOk(attribute); ok {
+			key = attribute
+			value = v.(string)
+		}
+	}
+
+	matches, err := findUsers(ctx, c, key, value)
+	if err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  err.Error(),
+			Detail:   fmt.Sprintf("Listing users with %s %q failed", key, value),
+		})
+	}
+
+	if len(matches) < 1 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "No such user",
+			Detail:   fmt.Sprintf("No user matches %s %q", key, value),
+		})
+	}
+	if len(matches) > 1 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Ambiguous user lookup",
+			Detail:   fmt.Sprintf("%d users match %s %q", len(matches), key, value),
+		})
+	}
+
+	s := dataSourceUser().Schema
+	for key, val := range matches[0] {
+		if _, ok := s[key]; !ok {
+			continue
+		}
+		if err := d.Set(key, val); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Failed to transfer data",
+				Detail:   err.Error(),
+			})
+		}
+	}
+
+	d.SetId(fmt.Sprint(matches[0]["iduser"]))
+
+	return diags
+}
+
+// findUsers lists the users whose attribute key, one of the userLookups, matches value.
+func findUsers(ctx context.Context, c *Client, key string, value string) ([]map[string]interface{}, error) {
+	users, err := c.listUsers(ctx, map[string]interface{}{userLookups[key]: []string{value}})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]map[string]interface{}, 0)
+	for _, user := range users {
+		oi := flattenUser(user)
+		if strings.EqualFold(fmt.Sprint(oi[key]), value) {
+			matches = append(matches, oi)
+		}
+	}
+
+	return matches, nil
+}
@@ -5,15 +5,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func dataSourceUserGroups() *schema.Resource {
+	group := map[string]*schema.Schema{
+		"idusergroup":        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"identifier":         &schema.Schema{Type: schema.TypeString, Computed: true},
+		"name":               &schema.Schema{Type: schema.TypeString, Computed: true},
+		"idusergroup_parent": &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_created":       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_modified":      &schema.Schema{Type: schema.TypeString, Computed: true},
+		"is_removed":         &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"idusers_primary": &schema.Schema{Type: schema.TypeList, Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			}},
+	}
+
 	return &schema.Resource{
 		ReadContext: dataSourceUserGroupsRead,
 		Schema: map[string]*schema.Schema{
@@ -25,24 +38,19 @@ func dataSourceUserGroups() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"sort_by": {
+				Type:             schema.TypeString,
+				Description:      "User group attribute to sort the results by. Defaults to `idusergroup`",
+				Optional:         true,
+				Default:          "idusergroup",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(schemaKeys(group), false)),
+			},
 			"groups": &schema.Schema{
 				Type:        schema.TypeList,
 				Computed:    true,
 				Description: "User group data from Mosyle",
 				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"idusergroup":        &schema.Schema{Type: schema.TypeString, Computed: true},
-						"identifier":         &schema.Schema{Type: schema.TypeString, Computed: true},
-						"name":               &schema.Schema{Type: schema.TypeString, Computed: true},
-						"idusergroup_parent": &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_created":       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"date_modified":      &schema.Schema{Type: schema.TypeString, Computed: true},
-						"is_removed":         &schema.Schema{Type: schema.TypeBool, Computed: true},
-						"idusers_primary": &schema.Schema{Type: schema.TypeList, Computed: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							}},
-					},
+					Schema: group,
 				},
 			},
 		},
@@ -69,7 +77,7 @@ func dataSourceUserGroupsRead(ctx context.Context, d *schema.ResourceData, m int
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/usergroups", c.HostURL), strings.NewReader(string(req_body)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/usergroups", c.HostURL), strings.NewReader(string(req_body)))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -83,7 +91,10 @@ func dataSourceUserGroupsRead(ctx context.Context, d *schema.ResourceData, m int
 		})
 	}
 
-	if err := d.Set("groups", flattenUserGroups(response)); err != nil {
+	groups := flattenUserGroups(response)
+	sortRecords(groups, d.Get("sort_by").(string))
+
+	if err := d.Set("groups", groups); err != nil {
 		return append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Failed to transfer data",
@@ -91,8 +102,7 @@ func dataSourceUserGroupsRead(ctx context.Context, d *schema.ResourceData, m int
 		})
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(queryID(map[string]interface{}{"options": options, "sort_by": d.Get("sort_by")}))
 
 	return diags
 }
@@ -109,10 +119,7 @@ func flattenUserGroups(response ListResponse) []interface{} {
 
 		for key, val := range user {
 			if strings.HasPrefix(key, "date_") {
-				ival, err := strconv.ParseInt(val.(string), 10, 64)
-				if err == nil {
-					t := time.Unix(ival, 0)
-					strDate := t.Format(time.RFC3339)
+				if strDate, err := coerceDate(val); err == nil {
 					oi[strings.ToLower(key)] = strDate
 					continue
 				}
@@ -2,43 +2,85 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func dataSourceUsers() *schema.Resource {
+	user := map[string]*schema.Schema{
+		"iduser":     &schema.Schema{Type: schema.TypeString, Computed: true},
+		"code":       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"name":       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"type":       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"identifier": &schema.Schema{Type: schema.TypeString, Computed: true},
+		"email":      &schema.Schema{Type: schema.TypeString, Computed: true},
+		"is_removed": &schema.Schema{Type: schema.TypeBool, Computed: true},
+	}
+
 	return &schema.Resource{
 		ReadContext: dataSourceUsersRead,
 		Schema: map[string]*schema.Schema{
+			"identifiers": {
+				Type:          schema.TypeList,
+				Description:   "Only list users with these identifiers",
+				Optional:      true,
+				ConflictsWith: []string{"filter"},
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateNotEmpty,
+				},
+			},
+			"emails": {
+				Type:          schema.TypeList,
+				Description:   "Only list users with these emails",
+				Optional:      true,
+				ConflictsWith: []string{"filter"},
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateEmail,
+				},
+			},
+			"types": {
+				Type:          schema.TypeList,
+				Description:   "Only list users of these types, each one of (ENDUSER|GROUP_ADMIN|ADMIN)",
+				Optional:      true,
+				ConflictsWith: []string{"filter"},
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateUserType,
+				},
+			},
+			"include_removed": {
+				Type:        schema.TypeBool,
+				Description: "Include users that have been removed. Defaults to `true`",
+				Optional:    true,
+				Default:     includeRemovedDefault,
+			},
 			"filter": {
 				Type:        schema.TypeMap,
 				Description: "Filters to limit API data",
 				Optional:    true,
+				Deprecated:  "Use the identifiers, emails and types attributes instead",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"sort_by": {
+				Type:             schema.TypeString,
+				Description:      "User attribute to sort the results by. Defaults to `identifier`",
+				Optional:         true,
+				Default:          "identifier",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(schemaKeys(user), false)),
+			},
 			"users": &schema.Schema{
 				Type:        schema.TypeList,
 				Computed:    true,
 				Description: "User data from Mosyle",
 				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"iduser":     &schema.Schema{Type: schema.TypeString, Computed: true},
-						"code":       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"name":       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"type":       &schema.Schema{Type: schema.TypeString, Computed: true},
-						"identifier": &schema.Schema{Type: schema.TypeString, Computed: true},
-						"email":      &schema.Schema{Type: schema.TypeString, Computed: true},
-						"is_removed": &schema.Schema{Type: schema.TypeBool, Computed: true},
-					},
+					Schema: user,
 				},
 			},
 		},
@@ -51,25 +93,25 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, m interfac
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	filter := d.Get("filter").(map[string]interface{})
-	req_body, err := json.Marshal(ListPostBody{
-		Operation: "list_users",
-		Options:   filter,
-	})
-	if err != nil {
-		return diag.FromErr(err)
+	options := make(map[string]interface{})
+	for key, value := range d.Get("filter").(map[string]interface{}) {
+		options[key] = value
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/users", c.HostURL), strings.NewReader(string(req_body)))
-	if err != nil {
-		return diag.FromErr(err)
+	for _, key := range []string{"identifiers", "emails", "types"} {
+		if values, ok := d.GetOk(key); ok {
+			options[key] = values
+		}
 	}
 
-	response, err := c.doRequest(req)
+	options["include_removed"] = d.Get("include_removed").(bool)
+
+	users, err := listUsersFiltered(ctx, c, options)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	sortRecords(users, d.Get("sort_by").(string))
 
-	if err := d.Set("users", flattenUsers(response)); err != nil {
+	if err := d.Set("users", users); err != nil {
 		return append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Failed to transfer data",
@@ -77,28 +119,54 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, m interfac
 		})
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(queryID(map[string]interface{}{"options": options, "sort_by": d.Get("sort_by")}))
 
 	return diags
 }
 
-func flattenUsers(response ListResponse) []map[string]interface{} {
-	if len(response.Response) < 1 || len(response.Response[0].Users) < 1 {
-		return make([]map[string]interface{}, 0)
-	}
+// includeRemovedDefault is whether users that have been removed are listed
+// when include_removed is not set.
+const includeRemovedDefault = true
 
-	ois := make([]map[string]interface{}, len(response.Response[0].Users), len(response.Response[0].Users))
+// listUsersFiltered runs list_users and flattens the users. When the
+// include_removed option is false, removed users are also left out of the
+// response in case the API ignores the option.
+func listUsersFiltered(ctx context.Context, c *Client, options map[string]interface{}) ([]map[string]interface{}, error) {
+	records, err := c.listUsers(ctx, options)
+	if err != nil {
+		return nil, err
+	}
 
-	for i, user := range response.Response[0].Users {
-		oi := make(map[string]interface{})
+	include_removed, _ := options["include_removed"].(bool)
 
-		for key, val := range user {
-			oi[strings.ToLower(key)] = val
+	users := make([]map[string]interface{}, 0, len(records))
+	for _, user := range flattenUsers(records) {
+		removed, _ := coerceBool(user["is_removed"])
+		if removed && !include_removed {
+			continue
 		}
+		users = append(users, user)
+	}
 
-		ois[i] = oi
+	return users, nil
+}
+
+func flattenUsers(users []map[string]interface{}) []map[string]interface{} {
+	ois := make([]map[string]interface{}, len(users), len(users))
+
+	for i, user := range users {
+		ois[i] = flattenUser(user)
 	}
 
 	return ois
 }
+
+func flattenUser(user map[string]interface{}) map[string]interface{} {
+	oi := make(map[string]interface{})
+
+	for key, val := range user {
+		oi[strings.ToLower(key)] = val
+	}
+
+	return oi
+}
@@ -0,0 +1,359 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// deviceSchema describes a single device record as returned by the Mosyle "list" operation.
+func deviceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"deviceudid":                       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"total_disk":                       &schema.Schema{Type: schema.TypeFloat, Computed: true},
+		"os":                               &schema.Schema{Type: schema.TypeString, Computed: true},
+		"serial_number":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+		"device_model_name":                &schema.Schema{Type: schema.TypeString, Computed: true},
+		"device_name":                      &schema.Schema{Type: schema.TypeString, Computed: true},
+		"device_model":                     &schema.Schema{Type: schema.TypeString, Computed: true},
+		"battery":                          &schema.Schema{Type: schema.TypeFloat, Computed: true},
+		"osversion":                        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"vpn_status":                       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"userid":                           &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_info":                        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"carrier":                          &schema.Schema{Type: schema.TypeString, Computed: true},
+		"roaming_enabled":                  &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"isroaming":                        &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"imei":                             &schema.Schema{Type: schema.TypeString, Computed: true},
+		"meid":                             &schema.Schema{Type: schema.TypeString, Computed: true},
+		"available_disk":                   &schema.Schema{Type: schema.TypeFloat, Computed: true},
+		"wifi_mac_address":                 &schema.Schema{Type: schema.TypeString, Computed: true},
+		"bluetooth_mac_address":            &schema.Schema{Type: schema.TypeString, Computed: true},
+		"is_supervised":                    &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"date_app_info":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_last_beat":                   &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_last_push":                   &schema.Schema{Type: schema.TypeString, Computed: true},
+		"status":                           &schema.Schema{Type: schema.TypeString, Computed: true},
+		"isactivationlockenabled":          &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"isdevicelocatorserviceenabled":    &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"isdonotdisturbineffect":           &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"iscloudbackupenabled":             &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"isnetworktethered":                &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"needosupdate":                     &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"productkeyupdate":                 &schema.Schema{Type: schema.TypeString, Computed: true},
+		"device_type":                      &schema.Schema{Type: schema.TypeString, Computed: true},
+		"lostmode_status":                  &schema.Schema{Type: schema.TypeString, Computed: true},
+		"is_muted":                         &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"date_muted":                       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"activation_bypass":                &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_media_info":                  &schema.Schema{Type: schema.TypeString, Computed: true},
+		"tags":                             &schema.Schema{Type: schema.TypeList, Computed: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"is_deleted":                       &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"itunesstoreaccounthash":           &schema.Schema{Type: schema.TypeString, Computed: true},
+		"itunesstoreaccountisactive":       &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"date_profiles_info":               &schema.Schema{Type: schema.TypeString, Computed: true},
+		"ethernet_mac_address":             &schema.Schema{Type: schema.TypeString, Computed: true},
+		"model_name":                       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"lastcloudbackupdate":              &schema.Schema{Type: schema.TypeString, Computed: true},
+		"systemintegrityprotectionenabled": &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"buildversion":                     &schema.Schema{Type: schema.TypeString, Computed: true},
+		"localhostname":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+		"hostname":                         &schema.Schema{Type: schema.TypeString, Computed: true},
+		"osupdatesettings":                 &schema.Schema{Type: schema.TypeString, Computed: true},
+		"activemanagedusers":               &schema.Schema{Type: schema.TypeString, Computed: true},
+		"currentconsolemanageduser":        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_printers":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+		"autosetupadminaccounts":           &schema.Schema{Type: schema.TypeString, Computed: true},
+		"appletvid":                        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"asset_tag":                        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"managementstatus":                 &schema.Schema{Type: schema.TypeString, Computed: true},
+		"osupdatestatus":                   &schema.Schema{Type: schema.TypeString, Computed: true},
+		"availableosupdates":               &schema.Schema{Type: schema.TypeString, Computed: true},
+		"has_password":                     &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"timezone":                         &schema.Schema{Type: schema.TypeString, Computed: true},
+		"activation_bypass_mdm":            &schema.Schema{Type: schema.TypeString, Computed: true},
+		"percent_disk":                     &schema.Schema{Type: schema.TypeFloat, Computed: true},
+		"idsharedgroup":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+		"enrollment_type":                  &schema.Schema{Type: schema.TypeString, Computed: true},
+		"status_login":                     &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_lastlogin":                   &schema.Schema{Type: schema.TypeString, Computed: true},
+		"idaccount":                        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_checkin":                     &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_enroll":                      &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_checkout":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+		"date_kinfo":                       &schema.Schema{Type: schema.TypeString, Computed: true},
+		"cpu_model":                        &schema.Schema{Type: schema.TypeString, Computed: true},
+		"hasvpn":                           &schema.Schema{Type: schema.TypeBool, Computed: true},
+		"installed_memory":                 &schema.Schema{Type: schema.TypeFloat, Computed: true},
+		"username":                         &schema.Schema{Type: schema.TypeString, Computed: true},
+		"usertype":                         &schema.Schema{Type: schema.TypeString, Computed: true},
+		"idusermosyle":                     &schema.Schema{Type: schema.TypeString, Computed: true},
+		"raw": &schema.Schema{
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "All attributes as returned by Mosyle, before conversion to their native types",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"extra_attributes": &schema.Schema{
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Attributes returned by Mosyle that are not part of this schema",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// deviceColumns lists the device attributes that can be requested through "specific_columns".
+func deviceColumns() []string {
+	s := deviceSchema()
+	delete(s, "extra_attributes")
+	delete(s, "raw")
+
+	return schemaKeys(s)
+}
+
+func flattenDevices(devices []map[string]interface{}) ([]interface{}, []string) {
+	ois := make([]interface{}, len(devices), len(devices))
+	warnings := make([]string, 0)
+
+	for i, device := range devices {
+		oi, w := flattenDevice(device)
+		ois[i] = oi
+		warnings = append(warnings, w...)
+	}
+
+	return ois, uniqueWarnings(warnings)
+}
+
+// selectColumns drops every attribute that was not asked for from flattened
+// records. The OS is always kept, results can span several of them.
+func selectColumns(ois []interface{}, columns []string) []interface{} {
+	for i, oi := range ois {
+		record := oi.(map[string]interface{})
+		selected := map[string]interface{}{"os": record["os"]}
+		for _, column := range columns {
+			if val, ok := record[column]; ok {
+				selected[column] = val
+			}
+		}
+
+		ois[i] = selected
+	}
+
+	return ois
+}
+
+// deviceAttributes caches deviceSchema for decoding, it is consulted for every
+// key of every device record.
+var deviceAttributes = deviceSchema()
+
+// flattenDevice converts a device record from the API into the shape of
+// deviceSchema. Values are coerced to the attribute type instead of asserted,
+// keys the schema does not know end up in extra_attributes. Values that cannot
+// be converted are left out and reported as warnings. The unconverted values
+// are kept in raw.
+func flattenDevice(device map[string]interface{}) (map[string]interface{}, []string) {
+	oi := make(map[string]interface{})
+	extra := make(map[string]interface{})
+	raw := make(map[string]interface{})
+	warnings := make([]string, 0)
+
+	for key, val := range device {
+		key = strings.ToLower(key)
+
+		str, err := coerceString(val)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", key, err))
+			continue
+		}
+		if val != nil {
+			raw[key] = str
+		}
+
+		attribute, ok := deviceAttributes[key]
+		if !ok || key == "extra_attributes" || key == "raw" {
+			extra[key] = str
+			continue
+		}
+
+		cval, err := coerceDeviceValue(key, attribute, val)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", key, err))
+			continue
+		}
+		if cval != nil {
+			oi[key] = cval
+		}
+	}
+
+	oi["extra_attributes"] = extra
+	oi["raw"] = raw
+
+	return oi, warnings
+}
+
+func coerceDeviceValue(key string, attribute *schema.Schema, val interface{}) (interface{}, error) {
+	switch attribute.Type {
+	case schema.TypeBool:
+		return coerceBool(val)
+	case schema.TypeFloat:
+		if val == nil {
+			return nil, nil
+		}
+		return coerceFloat(val)
+	case schema.TypeList:
+		return coerceList(val)
+	case schema.TypeString:
+		if val == nil {
+			return nil, nil
+		}
+		if strings.HasPrefix(key, "date_") {
+			return coerceDate(val)
+		}
+		return coerceString(val)
+	}
+
+	return val, nil
+}
+
+func coerceString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return "", fmt.Errorf("cannot convert %T to a string", val)
+	}
+
+	return string(b), nil
+}
+
+func coerceBool(val interface{}) (bool, error) {
+	switch v := val.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		if v == "" {
+			return false, nil
+		}
+		bval, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("cannot convert %q to a boolean", v)
+		}
+		return bval, nil
+	}
+
+	return false, fmt.Errorf("cannot convert %T to a boolean", val)
+}
+
+// coerceFloat converts numbers and numeric strings. A unit following the
+// number, as in "16 GB", is ignored.
+func coerceFloat(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		fields := strings.Fields(v)
+		if len(fields) < 1 {
+			return 0, nil
+		}
+		fval, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot convert %q to a number", v)
+		}
+		return fval, nil
+	}
+
+	return 0, fmt.Errorf("cannot convert %T to a number", val)
+}
+
+// coerceList converts comma separated strings and arrays to a list of strings.
+func coerceList(val interface{}) ([]interface{}, error) {
+	list := make([]interface{}, 0)
+
+	switch v := val.(type) {
+	case nil:
+		return list, nil
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	case []interface{}:
+		for _, item := range v {
+			str, err := coerceString(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, str)
+		}
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to a list", val)
+}
+
+// coerceDate converts a Unix timestamp to RFC 3339. Values that are not a
+// timestamp are passed on as a string.
+func coerceDate(val interface{}) (string, error) {
+	str, err := coerceString(val)
+	if err != nil {
+		return "", err
+	}
+
+	ival, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return str, nil
+	}
+
+	return time.Unix(ival, 0).Format(time.RFC3339), nil
+}
+
+// uniqueWarnings reports each distinct decoding problem once, no matter how
+// many records it occurred in.
+func uniqueWarnings(warnings []string) []string {
+	seen := make(map[string]bool)
+	for _, warning := range warnings {
+		seen[warning] = true
+	}
+
+	return schemaKeys(seen)
+}
+
+// decodeWarnings turns decoding problems into warnings.
+func decodeWarnings(warnings []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, warning := range uniqueWarnings(warnings) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unexpected device attribute value",
+			Detail:   warning,
+		})
+	}
+
+	return diags
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlattenDevice(t *testing.T) {
+	device, warnings := flattenDevice(map[string]interface{}{
+		"serial_number": "C02XK0AAJGH5",
+		"Battery":       "0.87",
+		"tags":          "loaner, classroom,",
+		"needosupdate":  "true",
+		"is_supervised": float64(1),
+		"is_muted":      []interface{}{"unexpected"},
+		"date_enroll":   float64(0),
+		"new_field":     true,
+	})
+
+	if device["serial_number"] != "C02XK0AAJGH5" {
+		t.Errorf("serial_number: got %v", device["serial_number"])
+	}
+	if device["battery"] != 0.87 {
+		t.Errorf("battery: got %v", device["battery"])
+	}
+	if tags := device["tags"].([]interface{}); len(tags) != 2 || tags[1] != "classroom" {
+		t.Errorf("tags: got %v", tags)
+	}
+	if device["needosupdate"] != true {
+		t.Errorf("needosupdate: got %v", device["needosupdate"])
+	}
+	if raw := device["raw"].(map[string]interface{}); raw["battery"] != "0.87" {
+		t.Errorf("raw: got %v", raw)
+	}
+	if device["is_supervised"] != true {
+		t.Errorf("is_supervised: got %v", device["is_supervised"])
+	}
+	if _, ok := device["is_muted"]; ok {
+		t.Errorf("is_muted: expected to be skipped, got %v", device["is_muted"])
+	}
+	if device["date_enroll"] != time.Unix(0, 0).Format(time.RFC3339) {
+		t.Errorf("date_enroll: got %v", device["date_enroll"])
+	}
+	if extra := device["extra_attributes"].(map[string]interface{}); extra["new_field"] != "true" {
+		t.Errorf("extra_attributes: got %v", extra)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", warnings)
+	}
+}
+
+func TestFlattenDeviceGroups(t *testing.T) {
+	groups := flattenDeviceGroups(DeviceGroupListResponse{Response: ListResult{DeviceGroups: []map[string]interface{}{
+		{"id": float64(12), "name": "Lab", "device_numbers": float64(3)},
+		{"id": "13", "name": "Library", "device_numbers": "7"},
+	}}})
+
+	for i, want := range []map[string]interface{}{
+		{"id": "12", "name": "Lab", "device_numbers": int64(3)},
+		{"id": "13", "name": "Library", "device_numbers": int64(7)},
+	} {
+		group := groups[i].(map[string]interface{})
+		for key, value := range want {
+			if group[key] != value {
+				t.Errorf("group %d: expected %s to be %#v, got %#v", i, key, value, group[key])
+			}
+		}
+	}
+}
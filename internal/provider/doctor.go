@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxClockSkew is how far the clock may be off from the API before it is
+// reported as a problem, tokens and certificates are checked against it.
+const maxClockSkew = time.Minute
+
+// DoctorCheck is the outcome of a single check of Client.Doctor. A check that
+// is not OK but Informational does not indicate a problem.
+type DoctorCheck struct {
+	Name          string `json:"check"`
+	OK            bool   `json:"ok"`
+	Informational bool   `json:"informational,omitempty"`
+	Detail        string `json:"detail"`
+}
+
+// doctorAuth describes a way of authenticating API requests.
+type doctorAuth struct {
+	name      string
+	authorize func(req *http.Request) error
+}
+
+// doctorEndpoints are the list operations Doctor tries, with options that
+// keep the response small.
+var doctorEndpoints = []struct {
+	path      string
+	operation string
+	options   map[string]interface{}
+}{
+	{"devices", "list", map[string]interface{}{"os": "mac", "page": 1, "specific_columns": []string{"serial_number"}}},
+	{"users", "list_users", map[string]interface{}{"page": 1}},
+	{"usergroups", "list_usergroup", map[string]interface{}{"page": 1}},
+	{"devicegroups", "list_devicegroup", map[string]interface{}{"page": 1}},
+}
+
+// Doctor checks whether the API can be reached with the credentials of the
+// client: the combination of credentials, DNS and TLS of the host, every way
+// of authenticating and every list endpoint. It also reports the clock skew
+// and any rate limit headers of the API. The checks continue after a failure,
+// so the report is complete.
+func (c *Client) Doctor() []DoctorCheck {
+	checks := make([]DoctorCheck, 0)
+
+	problems := credentialProblems(c.Auth.Username, c.Auth.Password, c.Auth.Token)
+	for _, problem := range problems {
+		checks = append(checks, DoctorCheck{Name: "credentials", Detail: problem.detail})
+	}
+	if len(problems) == 0 && c.Auth.Username == "" {
+		checks = append(checks, DoctorCheck{Name: "credentials", OK: true, Detail: "access token only"})
+	} else if len(problems) == 0 {
+		checks = append(checks, DoctorCheck{Name: "credentials", OK: true, Detail: "username, password and access token"})
+	}
+
+	host, err := url.Parse(c.HostURL)
+	if err != nil {
+		return append(checks, DoctorCheck{Name: "host", Detail: err.Error()})
+	}
+
+	addrs, err := net.LookupHost(host.Hostname())
+	if err != nil {
+		checks = append(checks, DoctorCheck{Name: "dns", Detail: err.Error()})
+	} else {
+		checks = append(checks, DoctorCheck{Name: "dns", OK: true, Detail: fmt.Sprintf("%s resolves to %s", host.Hostname(), strings.Join(addrs, ", "))})
+	}
+
+	checks = append(checks, c.checkTLS(host))
+
+	// A tenant only needs one way of authenticating to work, the others are
+	// reported for information unless none of them work
+	var authorize func(req *http.Request) error
+	var last *http.Response
+	auth_checks := make([]DoctorCheck, 0)
+	for _, auth := range c.doctorAuths() {
+		response, err := c.probe("users", "list_users", map[string]interface{}{"page": 1}, auth.authorize)
+		if response != nil {
+			last = response
+		}
+		if err != nil {
+			auth_checks = append(auth_checks, DoctorCheck{Name: "auth " + auth.name, Detail: err.Error()})
+			continue
+		}
+
+		auth_checks = append(auth_checks, DoctorCheck{Name: "auth " + auth.name, OK: true, Detail: "accepted"})
+		if authorize == nil {
+			authorize = auth.authorize
+		}
+	}
+	for _, check := range auth_checks {
+		if !check.OK && authorize != nil {
+			check.Informational = true
+			check.Detail = "not supported by this tenant: " + check.Detail
+		}
+		checks = append(checks, check)
+	}
+
+	for _, endpoint := range doctorEndpoints {
+		name := fmt.Sprintf("endpoint %s %s", endpoint.path, endpoint.operation)
+		if authorize == nil {
+			checks = append(checks, DoctorCheck{Name: name, Detail: "skipped, no way of authenticating works"})
+			continue
+		}
+
+		response, err := c.probe(endpoint.path, endpoint.operation, endpoint.options, authorize)
+		if response != nil {
+			last = response
+		}
+		if err != nil {
+			checks = append(checks, DoctorCheck{Name: name, Detail: err.Error()})
+		} else {
+			checks = append(checks, DoctorCheck{Name: name, OK: true, Detail: "reachable"})
+		}
+	}
+
+	if last != nil {
+		checks = append(checks, checkClockSkew(last.Header, time.Now()), checkRateLimits(last.Header))
+	}
+
+	return checks
+}
+
+// doctorAuths lists the ways of authenticating the client has credentials for.
+func (c *Client) doctorAuths() []doctorAuth {
+	auths := make([]doctorAuth, 0)
+
+	if c.Auth.Username != "" && c.Auth.Password != "" {
+		auths = append(auths, doctorAuth{"basic", func(req *http.Request) error {
+			req.Header.Add("Authorization", "Basic "+c.Auth.getAuth())
+			return nil
+		}})
+		auths = append(auths, doctorAuth{"bearer", func(req *http.Request) error {
+			token, _, err := c.login(req.Context())
+			if err != nil {
+				return fmt.Errorf("login: %w", err)
+			}
+			req.Header.Add("Authorization", "Bearer "+token)
+			return nil
+		}})
+	}
+	auths = append(auths, doctorAuth{"token only", func(req *http.Request) error {
+		return nil
+	}})
+
+	return auths
+}
+
+// probe runs a single page of a list operation and reports why it failed.
+// The response is returned whenever the API answered, for its headers.
+func (c *Client) probe(path string, operation string, options map[string]interface{}, authorize func(req *http.Request) error) (*http.Response, error) {
+	req_body, err := json.Marshal(ListPostBody{Operation: operation, Options: options})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s", c.HostURL, path), strings.NewReader(string(req_body)))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("accesstoken", c.Auth.Token)
+	req.Header.Add("User-Agent", "terraform-provider-mosyle "+c.Version)
+	if err := authorize(req); err != nil {
+		return nil, err
+	}
+
+	response, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return response, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response, fmt.Errorf("API response code %d (%s)", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	response_obj := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(b, &response_obj); err != nil {
+		return response, fmt.Errorf("response is not JSON: %w", err)
+	}
+	if response_obj.Status != "OK" {
+		return response, fmt.Errorf("API status %q %s", response_obj.Status, response_obj.Message)
+	}
+
+	return response, nil
+}
+
+// checkTLS connects to host through the transport of the client, so the
+// proxy and CA bundle the API requests use also apply to the handshake. Any
+// HTTP response will do, only the TLS connection is checked.
+func (c *Client) checkTLS(host *url.URL) DoctorCheck {
+	if host.Scheme != "https" {
+		return DoctorCheck{Name: "tls", OK: true, Detail: "skipped, the host does not use https"}
+	}
+
+	req, err := http.NewRequest("HEAD", host.Scheme+"://"+host.Host+"/", nil)
+	if err != nil {
+		return DoctorCheck{Name: "tls", Detail: err.Error()}
+	}
+	req.Header.Add("User-Agent", "terraform-provider-mosyle "+c.Version)
+
+	response, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return DoctorCheck{Name: "tls", Detail: err.Error()}
+	}
+	response.Body.Close()
+
+	state := response.TLS
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return DoctorCheck{Name: "tls", Detail: "the response was not received over TLS"}
+	}
+	cert := state.PeerCertificates[0]
+
+	return DoctorCheck{
+		Name:   "tls",
+		OK:     true,
+		Detail: fmt.Sprintf("%s, certificate for %s issued by %s expires %s", tls.VersionName(state.Version), cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.Format(time.RFC3339)),
+	}
+}
+
+// checkClockSkew compares the Date header of a response with now.
+func checkClockSkew(header http.Header, now time.Time) DoctorCheck {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return DoctorCheck{Name: "clock skew", OK: true, Detail: "unknown, the API did not send a Date header"}
+	}
+
+	skew := now.Sub(date).Round(time.Second)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		return DoctorCheck{Name: "clock skew", Detail: fmt.Sprintf("local clock is %s off from the API", skew)}
+	}
+
+	return DoctorCheck{Name: "clock skew", OK: true, Detail: fmt.Sprintf("local clock is %s off from the API", skew)}
+}
+
+// checkRateLimits reports the rate limit headers of a response.
+func checkRateLimits(header http.Header) DoctorCheck {
+	limits := make([]string, 0)
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if strings.Contains(name, "ratelimit") || strings.Contains(name, "rate-limit") || name == "retry-after" {
+			limits = append(limits, fmt.Sprintf("%s: %s", key, strings.Join(values, ", ")))
+		}
+	}
+	sort.Strings(limits)
+
+	if len(limits) == 0 {
+		return DoctorCheck{Name: "rate limits", OK: true, Detail: "the API did not send rate limit headers"}
+	}
+
+	return DoctorCheck{Name: "rate limits", OK: true, Detail: strings.Join(limits, "; ")}
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoctor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set("Authorization", "Bearer session")
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Basic ") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/devicegroups" {
+			w.Write([]byte(`{"status":"DENIED","message":"no access"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"OK","response":[]}`))
+	}))
+	defer server.Close()
+
+	username, password, token := "admin@example.com", "secret", "token"
+	c, _ := MosyleClient("dev", &username, &password, &token)
+	c.HostURL = server.URL
+
+	results := make(map[string]DoctorCheck)
+	for _, check := range c.Doctor() {
+		results[check.Name] = check
+	}
+
+	for name, ok := range map[string]bool{
+		"dns":                                    true,
+		"tls":                                    true,
+		"auth basic":                             true,
+		"auth bearer":                            false,
+		"auth token only":                        false,
+		"endpoint devices list":                  true,
+		"endpoint devicegroups list_devicegroup": false,
+		"clock skew":                             true,
+		"rate limits":                            true,
+	} {
+		check, found := results[name]
+		if !found {
+			t.Errorf("%s was not checked", name)
+			continue
+		}
+		if check.OK != ok {
+			t.Errorf("%s: expected ok %t, got %+v", name, ok, check)
+		}
+	}
+	for _, name := range []string{"auth bearer", "auth token only"} {
+		if !results[name].Informational {
+			t.Errorf("%s: expected an unsupported way of authenticating to be informational, got %+v", name, results[name])
+		}
+	}
+	if detail := results["rate limits"].Detail; detail != "X-Ratelimit-Remaining: 99" {
+		t.Errorf("unexpected rate limits %q", detail)
+	}
+	if detail := results["auth token only"].Detail; !strings.Contains(detail, "401") {
+		t.Errorf("expected the status code in %q", detail)
+	}
+}
+
+func TestDoctorNoAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	token := "token"
+	c, _ := MosyleClient("dev", nil, nil, &token)
+	c.HostURL = server.URL
+
+	for _, check := range c.Doctor() {
+		if check.Name == "auth token only" && (check.OK || check.Informational) {
+			t.Errorf("expected the only way of authenticating to fail, got %+v", check)
+		}
+	}
+}
+
+func TestCheckTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// The rejected handshake is expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	token := "token"
+	c, _ := MosyleClient("dev", nil, nil, &token)
+	host, _ := url.Parse(server.URL)
+
+	if check := c.checkTLS(host); check.OK {
+		t.Errorf("expected the test certificate to be rejected without a CA bundle, got %+v", check)
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	c.configureTransport(TransportConfig{CABundleFile: bundle})
+
+	if check := c.checkTLS(host); !check.OK {
+		t.Errorf("expected the CA bundle of the client to be trusted, got %+v", check)
+	}
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	header := http.Header{"Date": []string{now.Add(-5 * time.Minute).Format(http.TimeFormat)}}
+
+	if check := checkClockSkew(header, now); check.OK || check.Detail != "local clock is 5m0s off from the API" {
+		t.Errorf("expected 5 minutes of skew to fail, got %+v", check)
+	}
+	if check := checkClockSkew(header, now.Add(-5*time.Minute)); !check.OK {
+		t.Errorf("expected no skew to pass, got %+v", check)
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ ephemeral.EphemeralResourceWithConfigure = &sessionEphemeralResource{}
+
+func NewSessionEphemeralResource() ephemeral.EphemeralResource {
+	return &sessionEphemeralResource{}
+}
+
+type sessionEphemeralResource struct {
+	client *Client
+}
+
+type sessionEphemeralResourceModel struct {
+	Token     types.String `tfsdk:"token"`
+	ExpiresAt types.String `tfsdk:"expires_at"`
+}
+
+func (r *sessionEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_session"
+}
+
+func (r *sessionEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "A short-lived Mosyle API session, logged in with the credentials of the provider. The token is never written to the plan or state",
+		Attributes: map[string]schema.Attribute{
+			"token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Bearer token for the `Authorization` header of API requests",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the token expires, in RFC 3339 format",
+			},
+		},
+	}
+}
+
+func (r *sessionEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	c, ok := clientFromProviderData(req.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *Client, got %T", req.ProviderData))
+		return
+	}
+
+	r.client = c
+}
+
+func (r *sessionEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	// Configure leaves the client nil while the provider is not configured
+	if r.client == nil {
+		resp.Diagnostics.AddError("Provider not configured",
+			"mosyle_session logs in with the credentials of the provider, which has not been configured yet. The provider configuration may depend on values that are not known until apply.")
+		return
+	}
+
+	if r.client.Auth.Username == "" || r.client.Auth.Password == "" {
+		resp.Diagnostics.AddError("Missing credentials", "mosyle_session logs in with the username, password and accesstoken of the provider, configure all three")
+		return
+	}
+
+	token, expires, err := r.client.login(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Logging in to Mosyle as %q failed", r.client.Auth.Username))
+		return
+	}
+
+	data := sessionEphemeralResourceModel{
+		Token:     types.StringValue(token),
+		ExpiresAt: types.StringValue(expires.Format(time.RFC3339)),
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
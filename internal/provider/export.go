@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// labelInvalidChars matches everything that may not appear in a block label
+// that is also used as a resource address.
+var labelInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Export writes the users, assignments and device groups of the tenant as
+// mosyle_user, mosyle_assignment and mosyle_device_group resources with
+// import blocks, and its user groups as a local value, to one .tf file per
+// kind in dir. Users that could not be exported are reported as warnings.
+func Export(c *Client, dir string) ([]string, error) {
+	ctx := context.Background()
+
+	records, err := c.listUsers(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("listing users: %w", err)
+	}
+	users := make([]map[string]interface{}, 0, len(records))
+	for _, user := range flattenUsers(records) {
+		if removed, _ := coerceBool(user["is_removed"]); !removed {
+			users = append(users, user)
+		}
+	}
+	sortRecords(users, "identifier")
+
+	assignments, err := listAssignments(ctx, c, deviceOSes, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("listing assignments: %w", err)
+	}
+
+	user_groups, err := c.listUserGroups(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("listing user groups: %w", err)
+	}
+	sortRecords(user_groups, "name")
+
+	device_groups, err := c.listDeviceGroups(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("listing device groups: %w", err)
+	}
+	sortRecords(device_groups, "name")
+
+	users_file, warnings := exportUsers(users)
+
+	files := map[string]*hclwrite.File{
+		"users.tf":         users_file,
+		"assignments.tf":   exportAssignments(assignments),
+		"user_groups.tf":   exportUserGroups(user_groups),
+		"device_groups.tf": exportDeviceGroups(device_groups),
+	}
+	for name, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), hclwrite.Format(f.Bytes()), 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// exportUsers writes a mosyle_user resource per user. mosyle_user requires an
+// email for ADMIN and GROUP_ADMIN users, those without one are left out and
+// returned as warnings.
+func exportUsers(users []map[string]interface{}) (*hclwrite.File, []string) {
+	f := hclwrite.NewEmptyFile()
+	labels := make(map[string]bool)
+	var warnings []string
+
+	for _, user := range users {
+		identifier := fmt.Sprint(user["identifier"])
+		email, _ := coerceString(user["email"])
+		user_type, _ := coerceString(user["type"])
+		if user_type == "" {
+			user_type = "ENDUSER"
+		}
+
+		if user_type != "ENDUSER" && email == "" {
+			warnings = append(warnings, fmt.Sprintf("user %q is an %s without an email, it was not exported", identifier, user_type))
+			continue
+		}
+
+		if len(labels) > 0 {
+			f.Body().AppendNewline()
+		}
+		label := exportLabel(identifier, labels)
+
+		block := f.Body().AppendNewBlock("resource", []string{"mosyle_user", label}).Body()
+		block.SetAttributeValue("identifier", cty.StringVal(identifier))
+		block.SetAttributeValue("name", cty.StringVal(fmt.Sprint(user["name"])))
+		if email != "" {
+			block.SetAttributeValue("email", cty.StringVal(email))
+		}
+		if user_type != "ENDUSER" {
+			block.SetAttributeValue("type", cty.StringVal(user_type))
+		}
+		f.Body().AppendNewline()
+
+		appendImport(f.Body(), "mosyle_user", label, identifier)
+	}
+
+	return f, warnings
+}
+
+func exportAssignments(assignments []map[string]interface{}) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	labels := make(map[string]bool)
+
+	for i, assignment := range assignments {
+		if i > 0 {
+			f.Body().AppendNewline()
+		}
+
+		device_os, serial := fmt.Sprint(assignment["os"]), fmt.Sprint(assignment["device_serial"])
+		label := exportLabel(serial, labels)
+
+		block := f.Body().AppendNewBlock("resource", []string{"mosyle_assignment", label}).Body()
+		block.SetAttributeValue("os", cty.StringVal(device_os))
+		block.SetAttributeValue("device_serial", cty.StringVal(serial))
+		if identifier := fmt.Sprint(assignment["user_identifier"]); identifier != "" {
+			block.SetAttributeValue("user_identifier", cty.StringVal(identifier))
+		} else {
+			block.SetAttributeValue("iduser", cty.StringVal(fmt.Sprint(assignment["iduser"])))
+		}
+		f.Body().AppendNewline()
+
+		appendImport(f.Body(), "mosyle_assignment", label, device_os+"/"+serial)
+	}
+
+	return f
+}
+
+// exportDeviceGroups writes a mosyle_device_group resource per group, all of
+// its attributes are read from Mosyle so only the import block sets the id.
+func exportDeviceGroups(groups []map[string]interface{}) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	labels := make(map[string]bool)
+
+	for i, group := range groups {
+		if i > 0 {
+			f.Body().AppendNewline()
+		}
+
+		label := exportLabel(fmt.Sprint(group["name"]), labels)
+		f.Body().AppendNewBlock("resource", []string{"mosyle_device_group", label})
+		f.Body().AppendNewline()
+
+		appendImport(f.Body(), "mosyle_device_group", label, fmt.Sprint(group["id"]))
+	}
+
+	return f
+}
+
+// exportUserGroups writes the ids of the user groups as a local value, there
+// is no data source for a single user group.
+func exportUserGroups(groups []map[string]interface{}) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	labels := make(map[string]bool)
+
+	ids := make(map[string]cty.Value, len(groups))
+	for _, group := range groups {
+		ids[exportLabel(fmt.Sprint(group["name"]), labels)] = cty.StringVal(fmt.Sprint(group["idusergroup"]))
+	}
+
+	if len(ids) > 0 {
+		f.Body().AppendNewBlock("locals", nil).Body().SetAttributeValue("user_group_ids", cty.ObjectVal(ids))
+	}
+
+	return f
+}
+
+func appendImport(body *hclwrite.Body, resource_type, label, id string) {
+	block := body.AppendNewBlock("import", nil).Body()
+	block.SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resource_type},
+		hcl.TraverseAttr{Name: label},
+	})
+	block.SetAttributeValue("id", cty.StringVal(id))
+}
+
+// exportLabel turns a value into a unique block label, a number is appended
+// when the label has been used before.
+func exportLabel(value string, used map[string]bool) string {
+	label := strings.Trim(labelInvalidChars.ReplaceAllString(strings.ToLower(value), "_"), "_")
+	if label == "" || (label[0] >= '0' && label[0] <= '9') {
+		label = "_" + label
+	}
+
+	unique := label
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", label, i)
+	}
+	used[unique] = true
+
+	return unique
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	responses := map[string]string{
+		"list_users": `{"users":[
+			{"iduser":"1","identifier":"jdoe","name":"Jane Doe","email":"jane@example.com","type":"ENDUSER"},
+			{"iduser":"2","identifier":"admin","name":"Admin","email":"admin@example.com","type":"ADMIN"},
+			{"iduser":"4","identifier":"noemail","name":"No Email","type":"GROUP_ADMIN"},
+			{"iduser":"3","identifier":"gone","name":"Gone","is_removed":"1"}],"rows":3}`,
+		"list_usergroup":   `{"usergroups":[{"idusergroup":"7","name":"Staff"}],"rows":1}`,
+		"list_devicegroup": `{"devicegroups":[{"id":"9","name":"Lab Macs"}],"rows":1}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body ListPostBody
+		json.NewDecoder(r.Body).Decode(&body)
+
+		response := `{"rows":0}`
+		if body.Operation == "list" && body.Options["os"] == "mac" {
+			response = `{"devices":[{"serial_number":"C02XK1JKJGH5","deviceudid":"udid-1","idusermosyle":"1"}],"rows":1}`
+		} else if known, ok := responses[body.Operation]; ok {
+			response = known
+		}
+		w.Write([]byte(`{"status":"OK","response":[` + response + `]}`))
+	}))
+	defer server.Close()
+
+	c, _ := MosyleClient("dev", nil, nil, nil)
+	c.HostURL = server.URL
+
+	dir := t.TempDir()
+	warnings, err := Export(c, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"noemail"`) {
+		t.Errorf("expected a warning for the admin without an email, got %v", warnings)
+	}
+
+	for name, expected := range map[string][]string{
+		"users.tf": {
+			`resource "mosyle_user" "jdoe" {`,
+			`email      = "jane@example.com"`,
+			`email      = "admin@example.com"`,
+			`type       = "ADMIN"`,
+			`to = mosyle_user.admin`,
+		},
+		"assignments.tf": {
+			`resource "mosyle_assignment" "c02xk1jkjgh5" {`,
+			`user_identifier = "jdoe"`,
+			`id = "mac/C02XK1JKJGH5"`,
+		},
+		"user_groups.tf": {`staff = "7"`},
+		"device_groups.tf": {
+			`resource "mosyle_device_group" "lab_macs" {`,
+			`to = mosyle_device_group.lab_macs`,
+			`id = "9"`,
+		},
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range expected {
+			if !strings.Contains(string(b), line) {
+				t.Errorf("%s does not contain %q:\n%s", name, line, b)
+			}
+		}
+		if strings.Contains(string(b), "gone") {
+			t.Errorf("%s contains a removed user:\n%s", name, b)
+		}
+		if strings.Contains(string(b), "noemail") {
+			t.Errorf("%s contains an admin without an email:\n%s", name, b)
+		}
+	}
+}
+
+func TestExportLabel(t *testing.T) {
+	used := make(map[string]bool)
+	for value, expected := range map[string]string{
+		"Jane.Doe@example.com": "jane_doe_example_com",
+		"jane doe":             "jane_doe",
+		"42":                   "_42",
+	} {
+		if got := exportLabel(value, used); got != expected {
+			t.Errorf("%q: expected %q, got %q", value, expected, got)
+		}
+	}
+	if got := exportLabel("Jane Doe", used); got != "jane_doe_2" {
+		t.Errorf("expected a numbered label for a duplicate, got %q", got)
+	}
+}
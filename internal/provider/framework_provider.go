@@ -0,0 +1,265 @@
+package provider
+
+import (
+	"context"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+)
+
+var (
+	_ provider.ProviderWithFunctions          = &mosyleProvider{}
+	_ provider.ProviderWithEphemeralResources = &mosyleProvider{}
+	_ provider.ProviderWithListResources      = &mosyleProvider{}
+	_ provider.ProviderWithActions            = &mosyleProvider{}
+)
+
+// MuxServer serves the resources built on the plugin framework next to the
+// ones still built on the SDK, as a single provider.
+func MuxServer(ctx context.Context, version string) (func() tfprotov5.ProviderServer, error) {
+	providers := []func() tfprotov5.ProviderServer{
+		providerserver.NewProtocol5(NewFramework(version)()),
+		New(version)().GRPCProvider,
+	}
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+// NewFramework returns the part of the provider built on the plugin framework.
+// Its schema has to match the SDK provider in New exactly.
+func NewFramework(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &mosyleProvider{version: version}
+	}
+}
+
+type mosyleProvider struct {
+	version string
+}
+
+type mosyleProviderModel struct {
+	Username    types.String `tfsdk:"username"`
+	Password    types.String `tfsdk:"password"`
+	AccessToken types.String `tfsdk:"accesstoken"`
+
+	PasswordFile      types.String `tfsdk:"password_file"`
+	AccessTokenFile   types.String `tfsdk:"accesstoken_file"`
+	CredentialProcess types.String `tfsdk:"credential_process"`
+
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	CABundleFile       types.String `tfsdk:"ca_bundle_file"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	SkipCredentialsValidation types.Bool `tfsdk:"skip_credentials_validation"`
+}
+
+func (p *mosyleProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "mosyle"
+	resp.Version = p.version
+}
+
+func (p *mosyleProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"username": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Username used to log in to Mosyle",
+			},
+			"password": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Password used to log in to Mosyle",
+			},
+			"accesstoken": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Access Token from the Mosyle API integration",
+			},
+			"password_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "File to read the password from, instead of setting `password`",
+				Validators:          []validator.String{stringvalidator.ConflictsWith(path.MatchRoot("password"))},
+			},
+			"accesstoken_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "File to read the access token from, instead of setting `accesstoken`",
+				Validators:          []validator.String{stringvalidator.ConflictsWith(path.MatchRoot("accesstoken"))},
+			},
+			"credential_process": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Command that prints the credentials as JSON with `username`, `password` and `accesstoken` keys, run with `sh -c` or `cmd /C` on Windows. Only used for the credentials that are not set otherwise",
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Proxy to connect to Mosyle through, instead of the one from the `HTTPS_PROXY` environment variable",
+			},
+			"ca_bundle_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "File with PEM encoded certificates to trust on top of the system certificates, for proxies that intercept TLS",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Do not verify the TLS certificate of Mosyle. Only meant for emulators of the Mosyle API. Defaults to `false`",
+			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Configure the provider without checking that Mosyle accepts the credentials. Defaults to `false`",
+			},
+		},
+	}
+}
+
+func (p *mosyleProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config mosyleProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	username := stringOrEnv(config.Username, "MOSYLE_USERNAME")
+	password := stringOrEnv(config.Password, "MOSYLE_PASSWORD")
+	accesstoken := stringOrEnv(config.AccessToken, "MOSYLE_TOKEN")
+
+	username, password, accesstoken, problems := resolveCredentials(ctx, username, password, accesstoken,
+		config.PasswordFile.ValueString(), config.AccessTokenFile.ValueString(), config.CredentialProcess.ValueString())
+	addConfigProblems(&resp.Diagnostics, problems)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c, err := MosyleClient(p.version, &username, &password, &accesstoken)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create Mosyle client", err.Error())
+		return
+	}
+
+	addConfigProblems(&resp.Diagnostics, c.configureTransport(TransportConfig{
+		ProxyURL:           config.ProxyURL.ValueString(),
+		CABundleFile:       config.CABundleFile.ValueString(),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+	}))
+	if config.InsecureSkipVerify.ValueBool() {
+		resp.Diagnostics.AddAttributeWarning(path.Root("insecure_skip_verify"), "TLS certificate verification is disabled",
+			"Anyone between Terraform and the API can read the credentials. Only use insecure_skip_verify with an emulator of the Mosyle API.")
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Credentials that are only known during apply are checked then
+	known := true
+	for _, value := range []types.String{config.Username, config.Password, config.AccessToken, config.PasswordFile, config.AccessTokenFile, config.CredentialProcess} {
+		known = known && !value.IsUnknown()
+	}
+	if known {
+		addConfigProblems(&resp.Diagnostics, checkCredentials(ctx, c, config.SkipCredentialsValidation.ValueBool()))
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.DataSourceData = c
+	resp.ResourceData = c
+	resp.EphemeralResourceData = c
+	resp.ListResourceData = c
+	resp.ActionData = c
+}
+
+func (p *mosyleProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewUserResource,
+		NewAssignmentResource,
+		NewDeviceGroupResource,
+	}
+}
+
+func (p *mosyleProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		NewDevicesDataSource,
+	}
+}
+
+func (p *mosyleProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewSessionEphemeralResource,
+	}
+}
+
+func (p *mosyleProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewUserListResource,
+		NewAssignmentListResource,
+		NewDeviceGroupListResource,
+	}
+}
+
+func (p *mosyleProvider) Actions(ctx context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewRestartDeviceAction,
+		NewPlayLostModeSoundAction,
+		NewRefreshInventoryAction,
+		NewMoveToLimboAction,
+	}
+}
+
+func (p *mosyleProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewParseDateFunction,
+		NewSplitTagsFunction,
+		NewNormalizeSerialFunction,
+		NewNormalizeOSFunction,
+	}
+}
+
+// addConfigProblems reports configuration problems on their attribute.
+func addConfigProblems(diags *diag.Diagnostics, problems []configProblem) {
+	for _, problem := range problems {
+		if problem.attribute == "" {
+			diags.AddError(problem.summary, problem.detail)
+		} else {
+			diags.AddAttributeError(path.Root(problem.attribute), problem.summary, problem.detail)
+		}
+	}
+}
+
+// stringOrEnv falls back to an environment variable when an attribute is not
+// configured, like schema.EnvDefaultFunc does for the SDK provider.
+func stringOrEnv(value types.String, env string) string {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueString()
+	}
+
+	return os.Getenv(env)
+}
+
+// clientFromProviderData unwraps the client handed to resources and data
+// sources. It is nil while the provider is not configured yet.
+func clientFromProviderData(data any) (*Client, bool) {
+	if data == nil {
+		return nil, true
+	}
+
+	c, ok := data.(*Client)
+
+	return c, ok
+}
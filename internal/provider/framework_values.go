@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// attrTypes converts computed SDK attributes into their plugin framework
+// types, so records decoded for the SDK can be reused as framework objects.
+func attrTypes(s map[string]*schema.Schema) map[string]attr.Type {
+	objectTypes := make(map[string]attr.Type, len(s))
+	for key, attribute := range s {
+		objectTypes[key] = attrType(attribute)
+	}
+
+	return objectTypes
+}
+
+func attrType(attribute *schema.Schema) attr.Type {
+	switch attribute.Type {
+	case schema.TypeBool:
+		return types.BoolType
+	case schema.TypeFloat:
+		return types.Float64Type
+	case schema.TypeInt:
+		return types.Int64Type
+	case schema.TypeList:
+		return types.ListType{ElemType: attrType(attribute.Elem.(*schema.Schema))}
+	case schema.TypeMap:
+		return types.MapType{ElemType: attrType(attribute.Elem.(*schema.Schema))}
+	}
+
+	return types.StringType
+}
+
+// objectValue builds an object of the given type from a flattened record.
+// Missing primitives get their zero value and missing collections are null,
+// the same as the SDK stores them.
+func objectValue(objectTypes map[string]attr.Type, record map[string]interface{}) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	values := make(map[string]attr.Value, len(objectTypes))
+	for key, t := range objectTypes {
+		value, err := attrValue(t, record[key])
+		if err != nil {
+			diags.AddError("Failed to transfer data", fmt.Sprintf("%s: %s", key, err))
+			return types.ObjectNull(objectTypes), diags
+		}
+		values[key] = value
+	}
+
+	object, d := types.ObjectValue(objectTypes, values)
+	diags.Append(d...)
+
+	return object, diags
+}
+
+func attrValue(t attr.Type, val interface{}) (attr.Value, error) {
+	switch t := t.(type) {
+	case types.ListType:
+		if val == nil {
+			return types.ListNull(t.ElemType), nil
+		}
+		vals, err := coerceList(val)
+		if err != nil {
+			return nil, err
+		}
+		elems := make([]attr.Value, len(vals))
+		for i, v := range vals {
+			if elems[i], err = attrValue(t.ElemType, v); err != nil {
+				return nil, err
+			}
+		}
+		list, diags := types.ListValue(t.ElemType, elems)
+		if diags.HasError() {
+			return nil, fmt.Errorf("cannot convert %T to a list", val)
+		}
+		return list, nil
+	case types.MapType:
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			return types.MapNull(t.ElemType), nil
+		}
+		elems := make(map[string]attr.Value, len(vals))
+		for key, v := range vals {
+			elem, err := attrValue(t.ElemType, v)
+			if err != nil {
+				return nil, err
+			}
+			elems[key] = elem
+		}
+		m, diags := types.MapValue(t.ElemType, elems)
+		if diags.HasError() {
+			return nil, fmt.Errorf("cannot convert %T to a map", val)
+		}
+		return m, nil
+	}
+
+	switch t {
+	case types.BoolType:
+		b, err := coerceBool(val)
+		return types.BoolValue(b), err
+	case types.Float64Type:
+		if val == nil {
+			return types.Float64Value(0), nil
+		}
+		f, err := coerceFloat(val)
+		return types.Float64Value(f), err
+	case types.Int64Type:
+		if val == nil {
+			return types.Int64Value(0), nil
+		}
+		f, err := coerceFloat(val)
+		return types.Int64Value(int64(f)), err
+	}
+
+	s, err := coerceString(val)
+	return types.StringValue(s), err
+}
+
+// stringValues returns the elements of a list of strings, or nil when the
+// list is null or unknown.
+func stringValues(list types.List) []string {
+	if list.IsNull() || list.IsUnknown() {
+		return nil
+	}
+
+	values := make([]string, 0, len(list.Elements()))
+	for _, elem := range list.Elements() {
+		if s, ok := elem.(types.String); ok {
+			values = append(values, s.ValueString())
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// osAliases maps the names used for Apple operating systems outside of Mosyle
+// onto the OS names of the Mosyle "list" operation.
+var osAliases = map[string]string{
+	"macos":     "mac",
+	"osx":       "mac",
+	"macosx":    "mac",
+	"ipados":    "ios",
+	"iphoneos":  "ios",
+	"appletv":   "tvos",
+	"appletvos": "tvos",
+	"xros":      "visionos",
+}
+
+// normalizeOS maps an OS name or one of its osAliases onto one of deviceOSes.
+// Case, spaces, dashes and underscores are ignored.
+func normalizeOS(value string) (string, error) {
+	os := strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(value))
+	if alias, ok := osAliases[os]; ok {
+		os = alias
+	}
+
+	for _, known := range deviceOSes {
+		if os == known {
+			return os, nil
+		}
+	}
+
+	return "", fmt.Errorf("%q is not a known OS, expected one of %s", value, deviceOSList())
+}
+
+// normalizeSerial upper cases a serial number and drops the white-space and
+// dashes that labels and spreadsheets tend to add.
+func normalizeSerial(value string) (string, error) {
+	serial := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(value, "-", "")), ""))
+
+	if !serialNumberPattern.MatchString(serial) {
+		return "", fmt.Errorf("%q is not an Apple serial number of 8 to 14 letters and digits", value)
+	}
+
+	return serial, nil
+}
+
+// stringFunction implements a provider function that converts one string
+// argument with convert.
+type stringFunction struct {
+	name       string
+	definition function.Definition
+	convert    func(string) (interface{}, error)
+}
+
+func (f *stringFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = f.name
+}
+
+func (f *stringFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = f.definition
+}
+
+func (f *stringFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value string
+
+	resp.Error = req.Arguments.Get(ctx, &value)
+	if resp.Error != nil {
+		return
+	}
+
+	result, err := f.convert(value)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
+
+func NewParseDateFunction() function.Function {
+	return &stringFunction{
+		name: "parse_date",
+		definition: function.Definition{
+			Summary:             "Convert a Mosyle date to RFC 3339",
+			MarkdownDescription: "Converts a Unix timestamp, as Mosyle returns for `date_*` attributes, to an RFC 3339 date. Values that are not a timestamp are returned as they are.",
+			Parameters: []function.Parameter{
+				function.StringParameter{Name: "value", MarkdownDescription: "Unix timestamp in seconds"},
+			},
+			Return: function.StringReturn{},
+		},
+		convert: func(value string) (interface{}, error) {
+			return coerceDate(value)
+		},
+	}
+}
+
+func NewSplitTagsFunction() function.Function {
+	return &stringFunction{
+		name: "split_tags",
+		definition: function.Definition{
+			Summary:             "Split Mosyle tags into a list",
+			MarkdownDescription: "Splits the comma separated `tags` of a Mosyle device into a list, dropping white-space and empty tags.",
+			Parameters: []function.Parameter{
+				function.StringParameter{Name: "tags", MarkdownDescription: "Comma separated tags"},
+			},
+			Return: function.ListReturn{ElementType: types.StringType},
+		},
+		convert: func(value string) (interface{}, error) {
+			tags, err := coerceList(value)
+			if err != nil {
+				return nil, err
+			}
+
+			strs := make([]string, len(tags))
+			for i, tag := range tags {
+				strs[i] = tag.(string)
+			}
+
+			return strs, nil
+		},
+	}
+}
+
+func NewNormalizeSerialFunction() function.Function {
+	return &stringFunction{
+		name: "normalize_serial",
+		definition: function.Definition{
+			Summary:             "Normalize an Apple serial number",
+			MarkdownDescription: "Upper cases a serial number and removes white-space and dashes. Fails when the result is not an Apple serial number of 8 to 14 letters and digits.",
+			Parameters: []function.Parameter{
+				function.StringParameter{Name: "serial_number", MarkdownDescription: "Serial number to normalize"},
+			},
+			Return: function.StringReturn{},
+		},
+		convert: func(value string) (interface{}, error) {
+			return normalizeSerial(value)
+		},
+	}
+}
+
+func NewNormalizeOSFunction() function.Function {
+	return &stringFunction{
+		name: "normalize_os",
+		definition: function.Definition{
+			Summary:             "Map an OS name onto a Mosyle OS",
+			MarkdownDescription: "Maps an OS name such as `macOS`, `iPadOS` or `Apple TV` onto the OS names Mosyle uses, one of " + deviceOSList() + ". Fails for unknown names.",
+			Parameters: []function.Parameter{
+				function.StringParameter{Name: "os", MarkdownDescription: "OS name to normalize"},
+			},
+			Return: function.StringReturn{},
+		},
+		convert: func(value string) (interface{}, error) {
+			return normalizeOS(value)
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func runFunction(f function.Function, value string, result attr.Value) (attr.Value, *function.FuncError) {
+	resp := function.RunResponse{Result: function.NewResultData(result)}
+	f.Run(context.Background(), function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(value)}),
+	}, &resp)
+
+	return resp.Result.Value(), resp.Error
+}
+
+func TestParseDateFunction(t *testing.T) {
+	for value, want := range map[string]string{
+		"1700000000":           time.Unix(1700000000, 0).Format(time.RFC3339),
+		"2023-11-14T22:13:20Z": "2023-11-14T22:13:20Z",
+		"":                     "",
+	} {
+		got, err := runFunction(NewParseDateFunction(), value, types.StringUnknown())
+		if err != nil {
+			t.Fatalf("%q: %s", value, err)
+		}
+		if !got.Equal(types.StringValue(want)) {
+			t.Errorf("%q: expected %q, got %s", value, want, got)
+		}
+	}
+}
+
+func TestSplitTagsFunction(t *testing.T) {
+	got, err := runFunction(NewSplitTagsFunction(), "loaner, classroom,,", types.ListUnknown(types.StringType))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := types.ListValueFrom(context.Background(), types.StringType, []string{"loaner", "classroom"})
+	if !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestNormalizeSerialFunction(t *testing.T) {
+	got, err := runFunction(NewNormalizeSerialFunction(), " c02-xk0aa-jgh5\n", types.StringUnknown())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(types.StringValue("C02XK0AAJGH5")) {
+		t.Errorf("expected C02XK0AAJGH5, got %s", got)
+	}
+
+	if _, err := runFunction(NewNormalizeSerialFunction(), "ABC123", types.StringUnknown()); err == nil {
+		t.Errorf("expected an error for a short serial number")
+	}
+}
+
+func TestNormalizeOSFunction(t *testing.T) {
+	for value, want := range map[string]string{
+		"macOS":    "mac",
+		"Mac OS X": "mac",
+		"iPadOS":   "ios",
+		"Apple TV": "tvos",
+		"visionOS": "visionos",
+		"mac":      "mac",
+	} {
+		got, err := runFunction(NewNormalizeOSFunction(), value, types.StringUnknown())
+		if err != nil {
+			t.Fatalf("%q: %s", value, err)
+		}
+		if !got.Equal(types.StringValue(want)) {
+			t.Errorf("%q: expected %q, got %s", value, want, got)
+		}
+	}
+
+	if _, err := runFunction(NewNormalizeOSFunction(), "windows", types.StringUnknown()); err == nil {
+		t.Errorf("expected an error for an unknown OS")
+	}
+}
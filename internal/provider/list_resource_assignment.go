@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &assignmentListResource{}
+
+func NewAssignmentListResource() list.ListResource {
+	return &assignmentListResource{}
+}
+
+// assignmentListResource lists devices that are assigned to a user as
+// mosyle_assignment resources, it shares the type name and client with
+// assignmentResource.
+type assignmentListResource struct {
+	assignmentResource
+}
+
+type assignmentListResourceModel struct {
+	OperatingSystems types.List `tfsdk:"operating_systems"`
+	SerialNumbers    types.List `tfsdk:"serial_numbers"`
+	Tags             types.List `tfsdk:"tags"`
+}
+
+func (r *assignmentListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists devices that are assigned to a user, to generate `mosyle_assignment` configuration and import blocks",
+		Attributes: map[string]schema.Attribute{
+			"operating_systems": schema.ListAttribute{
+				MarkdownDescription: "Device OSes to list, each one of " + deviceOSList() + ". Defaults to all of them",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(osValidator),
+				},
+			},
+			"serial_numbers": schema.ListAttribute{
+				MarkdownDescription: "Only list devices with these serial numbers",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(serialNumberValidator)},
+			},
+			"tags": schema.ListAttribute{
+				MarkdownDescription: "Only list devices with these tags",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(notEmptyValidator)},
+			},
+		},
+	}
+}
+
+func (r *assignmentListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config assignmentListResourceModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	options := make(map[string]interface{})
+	for key, values := range map[string]types.List{
+		"serial_numbers": config.SerialNumbers,
+		"tags":           config.Tags,
+	} {
+		if len(values.Elements()) > 0 {
+			options[key] = stringValues(values)
+		}
+	}
+
+	oses := deviceOSes
+	if len(config.OperatingSystems.Elements()) > 0 {
+		oses = stringValues(config.OperatingSystems)
+	}
+
+	assignments, err := listAssignments(ctx, r.client, oses, options)
+	if err != nil {
+		diags.AddError(err.Error(), "Listing assignments failed")
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for i, assignment := range assignments {
+			if req.Limit > 0 && int64(i) >= req.Limit {
+				return
+			}
+
+			result := req.NewListResult(ctx)
+
+			var data assignmentResourceModel
+			if err := setAssignmentModel(&data, assignment); err != nil {
+				result.Diagnostics.AddError("Failed to transfer data", err.Error())
+				push(result)
+				return
+			}
+
+			// Only one of the user attributes may be configured, prefer the identifier
+			data.UserID = types.StringNull()
+			if data.UserIdentifier.ValueString() != "" {
+				data.IDUser = types.StringNull()
+			} else {
+				data.UserIdentifier = types.StringNull()
+			}
+
+			result.DisplayName = fmt.Sprintf("%s (%s)", data.DeviceSerial.ValueString(), data.OS.ValueString())
+			result.Diagnostics.Append(result.Identity.Set(ctx, data.identity())...)
+			if req.IncludeResource {
+				result.Diagnostics.Append(result.Resource.Set(ctx, &data)...)
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
+
+// listAssignments lists the devices of the given OSes that are assigned to a
+// user, as assignment records sorted by serial number. The user_identifier of
+// a record is set when the assigned user could be found.
+func listAssignments(ctx context.Context, c *Client, oses []string, options map[string]interface{}) ([]map[string]interface{}, error) {
+	device_options := map[string]interface{}{"specific_columns": []string{"deviceudid", "serial_number", "idusermosyle"}}
+	for key, value := range options {
+		device_options[key] = value
+	}
+
+	devices, err := c.listDevicesByOS(ctx, oses, device_options)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]map[string]interface{}, 0, len(devices))
+	idusers := make([]string, 0, len(devices))
+	for _, device := range devices {
+		assignment := assignmentRecord(device)
+		if assignment["iduser"] == "" {
+			continue
+		}
+		assignments = append(assignments, assignment)
+		idusers = append(idusers, assignment["iduser"].(string))
+	}
+	sortRecords(assignments, "device_serial")
+
+	if len(idusers) == 0 {
+		return assignments, nil
+	}
+
+	// Devices only know the Mosyle user id, look up all identifiers at once
+	records, err := c.listUsers(ctx, map[string]interface{}{"idusers": idusers})
+	if err != nil {
+		return nil, fmt.Errorf("listing assigned users: %w", err)
+	}
+	identifiers := make(map[string]string)
+	for _, user := range flattenUsers(records) {
+		identifiers[fmt.Sprint(user["iduser"])] = fmt.Sprint(user["identifier"])
+	}
+	for _, assignment := range assignments {
+		if identifier, ok := identifiers[assignment["iduser"].(string)]; ok {
+			assignment["user_identifier"] = identifier
+		}
+	}
+
+	return assignments, nil
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &deviceGroupListResource{}
+
+func NewDeviceGroupListResource() list.ListResource {
+	return &deviceGroupListResource{}
+}
+
+// deviceGroupListResource lists existing device groups as mosyle_device_group
+// resources, it shares the type name and client with deviceGroupResource.
+type deviceGroupListResource struct {
+	deviceGroupResource
+}
+
+type deviceGroupListResourceModel struct {
+	Names types.List `tfsdk:"names"`
+}
+
+func (r *deviceGroupListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists existing device groups, to generate `mosyle_device_group` configuration and import blocks",
+		Attributes: map[string]schema.Attribute{
+			"names": schema.ListAttribute{
+				MarkdownDescription: "Only list device groups with these names",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(notEmptyValidator)},
+			},
+		},
+	}
+}
+
+func (r *deviceGroupListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config deviceGroupListResourceModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	records, err := r.client.listDeviceGroups(ctx, map[string]interface{}{})
+	if err != nil {
+		diags.AddError(err.Error(), "Listing device groups failed")
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	// list_devicegroup has no options to filter on, the names are matched here
+	names := make(map[string]bool)
+	for _, name := range stringValues(config.Names) {
+		names[name] = true
+	}
+
+	groups := make([]map[string]interface{}, 0, len(records))
+	for _, group := range flattenDeviceGroups(DeviceGroupListResponse{Response: ListResult{DeviceGroups: records}}) {
+		oi := group.(map[string]interface{})
+		if name, _ := oi["name"].(string); len(names) > 0 && !names[name] {
+			continue
+		}
+		groups = append(groups, oi)
+	}
+	sortRecords(groups, "name")
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for i, group := range groups {
+			if req.Limit > 0 && int64(i) >= req.Limit {
+				return
+			}
+
+			result := req.NewListResult(ctx)
+
+			var data deviceGroupResourceModel
+			setDeviceGroupModel(&data, group)
+
+			result.DisplayName = fmt.Sprintf("%s (%s)", data.Name.ValueString(), data.ID.ValueString())
+			result.Diagnostics.Append(result.Identity.Set(ctx, deviceGroupIdentityModel{ID: data.ID})...)
+			if req.IncludeResource {
+				result.Diagnostics.Append(result.Resource.Set(ctx, &data)...)
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
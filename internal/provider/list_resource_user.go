@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &userListResource{}
+
+func NewUserListResource() list.ListResource {
+	return &userListResource{}
+}
+
+// userListResource lists existing users as mosyle_user resources, it shares
+// the type name and client with userResource.
+type userListResource struct {
+	userResource
+}
+
+type userListResourceModel struct {
+	Identifiers    types.List `tfsdk:"identifiers"`
+	Emails         types.List `tfsdk:"emails"`
+	Types          types.List `tfsdk:"types"`
+	IncludeRemoved types.Bool `tfsdk:"include_removed"`
+}
+
+func (r *userListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists existing users, to generate `mosyle_user` configuration and import blocks",
+		Attributes: map[string]schema.Attribute{
+			"identifiers": schema.ListAttribute{
+				MarkdownDescription: "Only list users with these identifiers",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(notEmptyValidator)},
+			},
+			"emails": schema.ListAttribute{
+				MarkdownDescription: "Only list users with these emails",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(emailValidator)},
+			},
+			"types": schema.ListAttribute{
+				MarkdownDescription: "Only list users of these types, each one of (ENDUSER|GROUP_ADMIN|ADMIN)",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(userTypeValidator)},
+			},
+			"include_removed": schema.BoolAttribute{
+				MarkdownDescription: "Include users that have been removed. Defaults to `true`",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *userListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config userListResourceModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	options := make(map[string]interface{})
+	for key, values := range map[string]types.List{
+		"identifiers": config.Identifiers,
+		"emails":      config.Emails,
+		"types":       config.Types,
+	} {
+		if len(values.Elements()) > 0 {
+			options[key] = stringValues(values)
+		}
+	}
+
+	options["include_removed"] = includeRemovedDefault
+	if !config.IncludeRemoved.IsNull() {
+		options["include_removed"] = config.IncludeRemoved.ValueBool()
+	}
+
+	users, err := listUsersFiltered(ctx, r.client, options)
+	if err != nil {
+		diags.AddError(err.Error(), "Listing users failed")
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	sortRecords(users, "identifier")
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for i, user := range users {
+			if req.Limit > 0 && int64(i) >= req.Limit {
+				return
+			}
+
+			result := req.NewListResult(ctx)
+
+			var data userResourceModel
+			if err := setUserModel(&data, user); err != nil {
+				result.Diagnostics.AddError("Failed to transfer data", err.Error())
+				push(result)
+				return
+			}
+			data.ID = data.Identifier
+
+			result.DisplayName = fmt.Sprintf("%s (%s)", data.Name.ValueString(), data.Identifier.ValueString())
+			result.Diagnostics.Append(result.Identity.Set(ctx, userIdentityModel{Identifier: data.Identifier})...)
+			if req.IncludeResource {
+				result.Diagnostics.Append(result.Resource.Set(ctx, &data)...)
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
@@ -1,16 +1,26 @@
 package provider
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const HostURL string = "https://businessapi.mosyle.com/v1"
 
+// maxConcurrentQueries bounds the number of list operations that run in parallel.
+const maxConcurrentQueries = 4
+
 // Client -
 type Client struct {
 	HostURL    string
@@ -28,17 +38,28 @@ type AuthStruct struct {
 
 func MosyleClient(version string, username, password, token *string) (*Client, error) {
 	c := Client{
-		HTTPClient: http.DefaultClient,
 		// Default Hashicups URL
 		HostURL: HostURL,
 		Version: version,
 	}
+	c.configureTransport(TransportConfig{})
 
-	// If username or password not provided, return empty client
+	// If username or password not provided, return a client that only uses the token
 	if username == nil || password == nil {
+		if token != nil {
+			c.Auth.Token = *token
+		}
 		return &c, nil
 	}
 
+	// Mosyle needs the access token next to the username and password
+	if *username != "" && *password != "" && (token == nil || *token == "") {
+		return nil, errors.New("an access token is required when logging in with a username and password")
+	}
+	if token == nil {
+		token = new(string)
+	}
+
 	c.Auth = AuthStruct{
 		Username: *username,
 		Password: *password,
@@ -48,26 +69,42 @@ func MosyleClient(version string, username, password, token *string) (*Client, e
 	return &c, nil
 }
 
-func (c *Client) doBaseRequest(req *http.Request) ([]byte, error) {
+// MosyleClientFromEnv creates a client with the credentials from the
+// MOSYLE_USERNAME, MOSYLE_PASSWORD and MOSYLE_TOKEN environment variables, the
+// same ones the provider falls back to. The username and password are optional.
+func MosyleClientFromEnv(version string) (*Client, error) {
+	username, password, token := os.Getenv("MOSYLE_USERNAME"), os.Getenv("MOSYLE_PASSWORD"), os.Getenv("MOSYLE_TOKEN")
+	if problems := credentialProblems(username, password, token); len(problems) > 0 {
+		return nil, errors.New(problems[0].detail)
+	}
+
+	return MosyleClient(version, &username, &password, &token)
+}
+
+func (c *Client) doBaseRequest(req *http.Request) (b []byte, err error) {
+	req, span := startRequestOperation(req)
+	defer func() { endOperation(span, err) }()
+
 	auth := c.Auth
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("accesstoken", auth.Token)
-	req.Header.Add("Authorization", "Basic "+auth.getAuth())
+	if auth.Username != "" {
+		req.Header.Add("Authorization", "Basic "+auth.getAuth())
+	}
 	req.Header.Add("User-Agent", "terraform-provider-mosyle "+c.Version)
 
 	response, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		return nil, errors.New("API response code " + fmt.Sprint(response.StatusCode) + " indicates failure")
 	}
 
-	defer response.Body.Close()
-
-	b, err := io.ReadAll(response.Body)
+	b, err = io.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +112,68 @@ func (c *Client) doBaseRequest(req *http.Request) ([]byte, error) {
 	return b, nil
 }
 
+// sessionLifetime is how long a token from the "login" endpoint stays valid
+// when the token itself does not say.
+const sessionLifetime = 24 * time.Hour
+
+// login exchanges the username and password for a bearer token, returned in
+// the Authorization header of the response, and reports when it expires.
+func (c *Client) login(ctx context.Context) (token string, expiry time.Time, err error) {
+	ctx, span := startOperation(ctx, "login", "login")
+	defer func() { endOperation(span, err) }()
+
+	auth := c.Auth
+
+	req_body, err := json.Marshal(map[string]string{"email": auth.Username, "password": auth.Password})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/login", c.HostURL), strings.NewReader(string(req_body)))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("accesstoken", auth.Token)
+	req.Header.Add("User-Agent", "terraform-provider-mosyle "+c.Version)
+
+	response, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", time.Time{}, errors.New("API response code " + fmt.Sprint(response.StatusCode) + " indicates failure")
+	}
+
+	token = strings.TrimSpace(strings.TrimPrefix(response.Header.Get("Authorization"), "Bearer"))
+	if token == "" {
+		return "", time.Time{}, errors.New("Login response did not contain a bearer token")
+	}
+
+	return token, tokenExpiry(token, time.Now()), nil
+}
+
+// tokenExpiry reads the "exp" claim of a JWT without verifying it, falling
+// back to sessionLifetime from now for tokens that are not a JWT.
+func tokenExpiry(token string, now time.Time) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err == nil {
+			claims := struct {
+				Exp int64 `json:"exp"`
+			}{}
+			if json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+				return time.Unix(claims.Exp, 0).UTC()
+			}
+		}
+	}
+
+	return now.Add(sessionLifetime).UTC()
+}
+
 func (c *Client) doRequest(req *http.Request) (ListResponse, error) {
 	bytes, err := c.doBaseRequest(req)
 	if err != nil {
@@ -94,41 +193,246 @@ func (c *Client) doRequest(req *http.Request) (ListResponse, error) {
 	return response_obj, nil
 }
 
+// listDevices runs the "list" operation against the devices endpoint.
+func (c *Client) listDevices(ctx context.Context, options map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.listAll(ctx, "devices", "list", options, func(result ListResult) []map[string]interface{} {
+		return result.Devices
+	})
+}
+
+// listDevicesByOS runs listDevices once per operating system, at most
+// maxConcurrentQueries at a time. The results are merged in the order of oses,
+// a device that shows up for more than one OS is only kept once and every
+// device is tagged with the OS it was listed for.
+func (c *Client) listDevicesByOS(ctx context.Context, oses []string, options map[string]interface{}) ([]map[string]interface{}, error) {
+	results := make([][]map[string]interface{}, len(oses))
+	errs := make([]error, len(oses))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < maxConcurrentQueries && w < len(oses); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				os_options := make(map[string]interface{}, len(options)+1)
+				for key, value := range options {
+					os_options[key] = value
+				}
+				os_options["os"] = oses[i]
+
+				results[i], errs[i] = c.listDevices(ctx, os_options)
+			}
+		}()
+	}
+	for i := range oses {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	devices := make([]map[string]interface{}, 0)
+	seen := make(map[string]bool)
+	for i, os := range oses {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("listing %s devices: %w", os, errs[i])
+		}
+
+		for _, device := range results[i] {
+			device["os"] = os
+
+			key := fmt.Sprint(device["deviceudid"])
+			if device["deviceudid"] == nil {
+				key = fmt.Sprint(device["serial_number"])
+			}
+			if device["deviceudid"] != nil || device["serial_number"] != nil {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+
+			devices = append(devices, device)
+		}
+	}
+
+	return devices, nil
+}
+
+// listUsers runs the "list_users" operation against the users endpoint.
+func (c *Client) listUsers(ctx context.Context, options map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.listAll(ctx, "users", "list_users", options, func(result ListResult) []map[string]interface{} {
+		return result.Users
+	})
+}
+
+// listUserGroups runs the "list_usergroup" operation against the user groups endpoint.
+func (c *Client) listUserGroups(ctx context.Context, options map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.listAll(ctx, "usergroups", "list_usergroup", options, func(result ListResult) []map[string]interface{} {
+		return result.UserGroups
+	})
+}
+
+// listDeviceGroups runs the "list_devicegroup" operation against the device groups endpoint.
+func (c *Client) listDeviceGroups(ctx context.Context, options map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.listAll(ctx, "devicegroups", "list_devicegroup", options, func(result ListResult) []map[string]interface{} {
+		return result.DeviceGroups
+	})
+}
+
+// listDeviceGroupDevices runs the "list_devices" operation for a single device group.
+func (c *Client) listDeviceGroupDevices(ctx context.Context, id string) ([]map[string]interface{}, error) {
+	return c.listAll(ctx, "devicegroups", "list_devices", map[string]interface{}{"iddevicegroup": id}, func(result ListResult) []map[string]interface{} {
+		return result.Devices
+	})
+}
+
+// deviceOperation runs an operation such as "change_to_limbo" against the
+// devices endpoint for the devices with the given UDIDs.
+func (c *Client) deviceOperation(ctx context.Context, operation string, devices []string) error {
+	req_body, err := json.Marshal(map[string]interface{}{"operation": operation, "devices": devices})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/devices", c.HostURL), strings.NewReader(string(req_body)))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doRequest(req)
+	return err
+}
+
+// listAll runs a paginated list operation and collects the records picked from
+// every page. All pages are fetched unless the options ask for a specific page.
+// The operation is a single span, with a child span for every page.
+func (c *Client) listAll(ctx context.Context, path string, operation string, options map[string]interface{}, records func(ListResult) []map[string]interface{}) (all []map[string]interface{}, err error) {
+	ctx, span := startOperation(ctx, path, operation)
+	pages := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("mosyle.pages", pages))
+		endOperation(span, err)
+	}()
+
+	all = make([]map[string]interface{}, 0)
+	_, single := options["page"]
+
+	for page := 1; ; page++ {
+		page_options := make(map[string]interface{}, len(options)+1)
+		for key, value := range options {
+			page_options[key] = value
+		}
+		if !single {
+			page_options["page"] = page
+		}
+
+		req_body, err := json.Marshal(ListPostBody{
+			Operation: operation,
+			Options:   page_options,
+		})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", c.HostURL, path), strings.NewReader(string(req_body)))
+		if err != nil {
+			return nil, err
+		}
+
+		pages++
+		b, err := c.doBaseRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		result, err := decodeListResult(b)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, records(result)...)
+		if single || lastPage(len(all), len(records(result)), result.Rows, result.PageSize) {
+			return all, nil
+		}
+	}
+}
+
+// lastPage reports whether a paginated listing is complete, based on the number
+// of records fetched so far, the size of the latest page and the totals the API
+// reported.
+func lastPage(fetched, received, rows, pageSize int) bool {
+	if received == 0 {
+		return true
+	}
+	if rows > 0 {
+		return fetched >= rows
+	}
+
+	return pageSize == 0 || received < pageSize
+}
+
+// decodeListResult decodes a list operation response. Most endpoints wrap the
+// result in an array, the device group endpoint returns a bare object.
+func decodeListResult(b []byte) (ListResult, error) {
+	response_obj := struct {
+		Status   string          `json:"status"`
+		Response json.RawMessage `json:"response"`
+	}{}
+	err := json.Unmarshal(b, &response_obj)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	if response_obj.Status != "OK" {
+		return ListResult{}, errors.New("Non succesful API call")
+	}
+
+	if len(response_obj.Response) > 0 && response_obj.Response[0] != '[' {
+		result := ListResult{}
+		err = json.Unmarshal(response_obj.Response, &result)
+		return result, err
+	}
+
+	results := []ListResult{}
+	if len(response_obj.Response) > 0 {
+		err = json.Unmarshal(response_obj.Response, &results)
+		if err != nil {
+			return ListResult{}, err
+		}
+	}
+	if len(results) < 1 {
+		return ListResult{}, nil
+	}
+
+	return results[0], nil
+}
+
 func (a *AuthStruct) getAuth() string {
 	base := a.Username + ":" + a.Password
 	return base64.StdEncoding.EncodeToString([]byte(base))
 }
 
 type ListResponse struct {
-	Status   string `json:"status"`
-	Response []struct {
-		Devices    []map[string]interface{} `json:"devices,omitempty"`
-		Users      []map[string]interface{} `json:"users,omitempty"`
-		UserGroups []map[string]interface{} `json:"usergroups,omitempty"`
-		UserId     string                   `json:"iduser,omitempty"`
-		Serial     string                   `json:"serialnumber,omitempty"`
-		Rows       int                      `json:"rows"`
-		PageSize   int                      `json:"page_size"`
-		Page       int                      `json:"page"`
-	} `json:"response"`
+	Status   string       `json:"status"`
+	Response []ListResult `json:"response"`
+}
+
+type ListResult struct {
+	Devices      []map[string]interface{} `json:"devices,omitempty"`
+	Users        []map[string]interface{} `json:"users,omitempty"`
+	UserGroups   []map[string]interface{} `json:"usergroups,omitempty"`
+	DeviceGroups []map[string]interface{} `json:"devicegroups,omitempty"`
+	UserId       string                   `json:"iduser,omitempty"`
+	Serial       string                   `json:"serialnumber,omitempty"`
+	Rows         int                      `json:"rows"`
+	PageSize     int                      `json:"page_size"`
+	Page         int                      `json:"page"`
 }
 
 type DeviceGroupListResponse struct {
-	Status   string `json:"status"`
-	Response struct {
-		DeviceGroups []map[string]interface{} `json:"devicegroups,omitempty"`
-		Rows         int                      `json:"rows"`
-		PageSize     int                      `json:"page_size"`
-		Page         int                      `json:"page"`
-	} `json:"response"`
+	Status   string     `json:"status"`
+	Response ListResult `json:"response"`
 }
 
 type ListPostBody struct {
 	Operation string                 `json:"operation"`
 	Options   map[string]interface{} `json:"options"`
 }
-
-type ListUserBody struct {
-	Operation string              `json:"operation"`
-	Options   map[string][]string `json:"options"`
-}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLogin(t *testing.T) {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"exp":1700000000}`))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/login" || r.Header.Get("accesstoken") != "token" || body["email"] != "admin@example.com" || body["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Authorization", "Bearer header."+claims+".signature")
+	}))
+	defer server.Close()
+
+	username, password, token := "admin@example.com", "secret", "token"
+	c, _ := MosyleClient("dev", &username, &password, &token)
+	c.HostURL = server.URL
+
+	bearer, expires, err := c.login(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bearer != "header."+claims+".signature" {
+		t.Errorf("token: got %q", bearer)
+	}
+	if !expires.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("expires: got %s", expires)
+	}
+
+	c.Auth.Password = "wrong"
+	if _, _, err := c.login(context.Background()); err == nil {
+		t.Errorf("expected an error for a rejected login")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	if got := tokenExpiry("opaque", now); !got.Equal(now.Add(sessionLifetime)) {
+		t.Errorf("expected the default lifetime for an opaque token, got %s", got)
+	}
+}
+
+func TestDeviceOperation(t *testing.T) {
+	var body struct {
+		Operation string   `json:"operation"`
+		Devices   []string `json:"devices"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/devices" || r.Method != "POST" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer server.Close()
+
+	c, _ := MosyleClient("dev", nil, nil, nil)
+	c.HostURL = server.URL
+
+	if err := c.deviceOperation(context.Background(), "restart_devices", []string{"udid-1", "udid-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if body.Operation != "restart_devices" || len(body.Devices) != 2 || body.Devices[1] != "udid-2" {
+		t.Errorf("unexpected request body %+v", body)
+	}
+}
+
+func TestListUsersFiltered(t *testing.T) {
+	var body ListPostBody
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"status":"OK","response":[{"users":[{"identifier":"kept","is_removed":"0"},{"identifier":"gone","is_removed":"1"}],"rows":2}]}`))
+	}))
+	defer server.Close()
+
+	c, _ := MosyleClient("dev", nil, nil, nil)
+	c.HostURL = server.URL
+
+	users, err := listUsersFiltered(context.Background(), c, map[string]interface{}{"include_removed": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.Options["include_removed"] != false {
+		t.Errorf("expected include_removed to be sent to list_users, got %+v", body.Options)
+	}
+	if len(users) != 1 || users[0]["identifier"] != "kept" {
+		t.Errorf("expected removed users to be left out, got %v", users)
+	}
+}
+
+func TestMosyleClientWithoutToken(t *testing.T) {
+	username, password := "admin@example.com", "secret"
+	empty := ""
+	if _, err := MosyleClient("dev", &username, &password, &empty); err == nil {
+		t.Errorf("expected an error for a username and password with an empty access token")
+	}
+	if _, err := MosyleClient("dev", &username, &password, nil); err == nil {
+		t.Errorf("expected an error for a username and password without an access token")
+	}
+	if _, err := MosyleClient("dev", &empty, &empty, &empty); err != nil {
+		t.Errorf("expected a client without credentials, got %s", err)
+	}
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -47,14 +48,50 @@ func New(version string) func() *schema.Provider {
 					Description: "Access Token from the Mosyle API integration",
 					DefaultFunc: schema.EnvDefaultFunc("MOSYLE_TOKEN", nil),
 				},
+				"password_file": &schema.Schema{
+					Type:          schema.TypeString,
+					Optional:      true,
+					ConflictsWith: []string{"password"},
+					Description:   "File to read the password from, instead of setting `password`",
+				},
+				"accesstoken_file": &schema.Schema{
+					Type:          schema.TypeString,
+					Optional:      true,
+					ConflictsWith: []string{"accesstoken"},
+					Description:   "File to read the access token from, instead of setting `accesstoken`",
+				},
+				"credential_process": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Command that prints the credentials as JSON with `username`, `password` and `accesstoken` keys, run with `sh -c` or `cmd /C` on Windows. Only used for the credentials that are not set otherwise",
+				},
+				"proxy_url": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Proxy to connect to Mosyle through, instead of the one from the `HTTPS_PROXY` environment variable",
+				},
+				"ca_bundle_file": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "File with PEM encoded certificates to trust on top of the system certificates, for proxies that intercept TLS",
+				},
+				"insecure_skip_verify": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Do not verify the TLS certificate of Mosyle. Only meant for emulators of the Mosyle API. Defaults to `false`",
+				},
+				"skip_credentials_validation": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Configure the provider without checking that Mosyle accepts the credentials. Defaults to `false`",
+				},
 			},
-			ResourcesMap: map[string]*schema.Resource{
-				"mosyle_user":       resourceUser(),
-				"mosyle_assignment": resourceAssignment(),
-			},
+			ResourcesMap: map[string]*schema.Resource{},
 			DataSourcesMap: map[string]*schema.Resource{
-				"mosyle_devices":      dataSourceDevices(),
+				"mosyle_device":       dataSourceDevice(),
+				"mosyle_devicegroup":  dataSourceDeviceGroup(),
 				"mosyle_devicegroups": dataSourceDeviceGroups(),
+				"mosyle_user":         dataSourceUser(),
 				"mosyle_users":        dataSourceUsers(),
 				"mosyle_usergroups":   dataSourceUserGroups(),
 			},
@@ -81,17 +118,49 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 		// Warning or errors can be collected in a slice type
 		var diags diag.Diagnostics
 
-		if (username != "") && (password != "") && (accesstoken != "") {
-			c, err := MosyleClient(version, &username, &password, &accesstoken)
-
-			return c, diag.FromErr(err)
+		// The mux server configures the framework provider first and stops at
+		// its first error, so these problems are only reported once. The
+		// credentials are checked against Mosyle by the framework provider.
+		username, password, accesstoken, problems := resolveCredentials(ctx, username, password, accesstoken,
+			d.Get("password_file").(string), d.Get("accesstoken_file").(string), d.Get("credential_process").(string))
+		diags = append(diags, configDiagnostics(problems)...)
+		if diags.HasError() {
+			return nil, diags
 		}
 
-		c, err := MosyleClient(version, nil, nil, nil)
+		c, err := MosyleClient(version, &username, &password, &accesstoken)
 		if err != nil {
 			return nil, diag.FromErr(err)
 		}
+		diags = append(diags, configDiagnostics(c.configureTransport(TransportConfig{
+			ProxyURL:           d.Get("proxy_url").(string),
+			CABundleFile:       d.Get("ca_bundle_file").(string),
+			InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+		}))...)
+		if diags.HasError() {
+			return nil, diags
+		}
 
 		return c, diags
 	}
 }
+
+// configDiagnostics reports configuration problems as errors on the attribute
+// they come from, like addConfigProblems does for the framework provider.
+func configDiagnostics(problems []configProblem) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, problem := range problems {
+		d := diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  problem.summary,
+			Detail:   problem.detail,
+		}
+		if problem.attribute != "" {
+			d.AttributePath = cty.GetAttrPath(problem.attribute)
+		}
+		diags = append(diags, d)
+	}
+
+	return diags
+}
@@ -1,9 +1,21 @@
 package provider
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 // providerFactories are used to instantiate a provider during acceptance testing.
@@ -21,8 +33,136 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func TestMuxServer(t *testing.T) {
+	server, err := MuxServer(context.Background(), "dev")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The mux server rejects framework and SDK providers with different schemas here
+	resp, err := server().GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+
+	for _, name := range []string{"mosyle_user", "mosyle_assignment", "mosyle_device_group"} {
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("resource %s is not served", name)
+		}
+	}
+	for _, name := range []string{"mosyle_device", "mosyle_devices", "mosyle_user", "mosyle_users"} {
+		if _, ok := resp.DataSourceSchemas[name]; !ok {
+			t.Errorf("data source %s is not served", name)
+		}
+	}
+	for _, name := range []string{"mosyle_user", "mosyle_assignment", "mosyle_device_group"} {
+		if _, ok := resp.ListResourceSchemas[name]; !ok {
+			t.Errorf("list resource %s is not served", name)
+		}
+	}
+	for _, name := range []string{"mosyle_restart_device", "mosyle_play_lost_mode_sound", "mosyle_refresh_inventory", "mosyle_move_to_limbo"} {
+		if _, ok := resp.ActionSchemas[name]; !ok {
+			t.Errorf("action %s is not served", name)
+		}
+	}
+	if _, ok := resp.EphemeralResourceSchemas["mosyle_session"]; !ok {
+		t.Errorf("ephemeral resource mosyle_session is not served")
+	}
+}
+
+func TestProviderConfigureProblems(t *testing.T) {
+	dir := t.TempDir()
+
+	for attribute, value := range map[string]string{
+		"accesstoken_file": filepath.Join(dir, "missing"),
+		"password_file":    filepath.Join(dir, "missing"),
+		"ca_bundle_file":   filepath.Join(dir, "missing.pem"),
+		"proxy_url":        "not a url",
+	} {
+		p := New("dev")()
+		config := map[string]interface{}{"accesstoken": "token", attribute: value}
+		if attribute == "accesstoken_file" {
+			delete(config, "accesstoken")
+		}
+
+		diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(config))
+		if !diags.HasError() {
+			t.Errorf("%s: expected an error for %q", attribute, value)
+			continue
+		}
+		if path := diags[0].AttributePath; !path.Equals(cty.GetAttrPath(attribute)) {
+			t.Errorf("%s: expected the error on the attribute, got %#v", attribute, path)
+		}
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestAssignmentDeviceChangePlan(t *testing.T) {
+	ctx := context.Background()
+
+	var resp fwresource.SchemaResponse
+	NewAssignmentResource().Schema(ctx, fwresource.SchemaRequest{}, &resp)
+	objectType := resp.Schema.Type().TerraformType(ctx)
+
+	state := tfsdk.State{Schema: resp.Schema, Raw: tftypes.NewValue(objectType, nil)}
+	state.Set(ctx, assignmentResourceModel{
+		ID:             types.StringValue("C02XK1JKJGH5"),
+		OS:             types.StringValue("mac"),
+		UserID:         types.StringValue("1"),
+		UserIdentifier: types.StringValue("jdoe"),
+		IDUser:         types.StringValue("1"),
+		DeviceSerial:   types.StringValue("C02XK1JKJGH5"),
+		DeviceUDID:     types.StringValue("udid-1"),
+	})
+
+	for serial, expected := range map[string]types.String{
+		"C02XK1JKJGH5": types.StringValue("udid-1"),
+		"C02XK1JKJGH6": types.StringUnknown(),
+	} {
+		plan := tfsdk.Plan{Schema: resp.Schema, Raw: tftypes.NewValue(objectType, nil)}
+		plan.Set(ctx, assignmentResourceModel{
+			ID:             types.StringUnknown(),
+			OS:             types.StringValue("mac"),
+			UserID:         types.StringUnknown(),
+			UserIdentifier: types.StringValue("jdoe"),
+			IDUser:         types.StringUnknown(),
+			DeviceSerial:   types.StringValue(serial),
+			DeviceUDID:     types.StringUnknown(),
+		})
+
+		req := planmodifier.StringRequest{
+			Path:        path.Root("device_udid"),
+			Plan:        plan,
+			PlanValue:   types.StringUnknown(),
+			State:       state,
+			StateValue:  types.StringValue("udid-1"),
+			ConfigValue: types.StringNull(),
+		}
+		modified := planmodifier.StringResponse{PlanValue: req.PlanValue}
+		sameDeviceStateForUnknown{}.PlanModifyString(ctx, req, &modified)
+
+		if modified.Diagnostics.HasError() {
+			t.Fatalf("%s: %v", serial, modified.Diagnostics)
+		}
+		if !modified.PlanValue.Equal(expected) {
+			t.Errorf("%s: expected device_udid to be planned as %s, got %s", serial, expected, modified.PlanValue)
+		}
+	}
+}
+
+func TestSessionWithoutProvider(t *testing.T) {
+	var resp ephemeral.OpenResponse
+	NewSessionEphemeralResource().Open(context.Background(), ephemeral.OpenRequest{}, &resp)
+
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Provider not configured" {
+		t.Errorf("expected an error for an unconfigured provider, got %v", resp.Diagnostics)
+	}
+}
@@ -3,147 +3,466 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceAssignment() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceAssignmentCreate,
-		ReadContext:   resourceAssignmentRead,
-		UpdateContext: resourceAssignmentUpdate,
-		DeleteContext: resourceAssignmentDelete,
-		Description:   "Assignment data",
-		Schema: map[string]*schema.Schema{
-			"os":            &schema.Schema{Type: schema.TypeString, Required: true, Description: "Assignment device os"},
-			"user_id":       &schema.Schema{Type: schema.TypeString, Required: true, Description: "Assignment user identifier"},
-			"device_serial": &schema.Schema{Type: schema.TypeString, Required: true, Description: "Assigned device"},
-			"device_udid":   &schema.Schema{Type: schema.TypeString, Computed: true, Description: "Device UDID"},
+var (
+	_ resource.ResourceWithConfigure   = &assignmentResource{}
+	_ resource.ResourceWithIdentity    = &assignmentResource{}
+	_ resource.ResourceWithImportState = &assignmentResource{}
+)
+
+func NewAssignmentResource() resource.Resource {
+	return &assignmentResource{}
+}
+
+type assignmentResource struct {
+	client *Client
+}
+
+type assignmentResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	OS             types.String `tfsdk:"os"`
+	UserID         types.String `tfsdk:"user_id"`
+	UserIdentifier types.String `tfsdk:"user_identifier"`
+	IDUser         types.String `tfsdk:"iduser"`
+	DeviceSerial   types.String `tfsdk:"device_serial"`
+	DeviceUDID     types.String `tfsdk:"device_udid"`
+}
+
+type assignmentIdentityModel struct {
+	OS           types.String `tfsdk:"os"`
+	DeviceSerial types.String `tfsdk:"device_serial"`
+}
+
+func (r *assignmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_assignment"
+	// Changing os or device_serial assigns the other device in place
+	resp.ResourceBehavior.MutableIdentity = true
+}
+
+func (r *assignmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	computed := []planmodifier.String{sameDeviceStateForUnknown{}}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Assignment data",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The ID of this resource.",
+				PlanModifiers:       computed,
+			},
+			"os": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Assignment device os, one of " + deviceOSList(),
+				Validators:          []validator.String{osValidator},
+			},
+			"user_id": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				DeprecationMessage:  "Use user_identifier or iduser instead",
+				MarkdownDescription: "Assigned user, either the identifier or the Mosyle user id",
+				Validators: []validator.String{
+					notEmptyValidator,
+					stringvalidator.ExactlyOneOf(path.MatchRoot("user_identifier"), path.MatchRoot("iduser")),
+				},
+			},
+			"user_identifier": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Identifier, set by admin, of the assigned user",
+				Validators:          []validator.String{notEmptyValidator},
+			},
+			"iduser": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Mosyle user id of the assigned user",
+				Validators:          []validator.String{notEmptyValidator},
+			},
+			"device_serial": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Assigned device",
+				Validators:          []validator.String{serialNumberValidator},
+			},
+			"device_udid": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Device UDID",
+				PlanModifiers:       computed,
+			},
 		},
 	}
 }
 
-func resourceAssignmentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Client)
+func (r *assignmentResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"os": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Assignment device os, one of " + deviceOSList(),
+			},
+			"device_serial": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Assigned device",
+			},
+		},
+	}
+}
 
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+func (r *assignmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	c, ok := clientFromProviderData(req.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *Client, got %T", req.ProviderData))
+		return
+	}
 
-	id := d.Get("user_id").(string)
-	serial := d.Get("device_serial").(string)
+	r.client = c
+}
 
-	assign_data := map[string]string{"iduser": id, "serialnumber": serial}
-	data := map[string]interface{}{"operation": "assign_device_user", "assign": [...]map[string]string{assign_data}}
-	req_body, err := json.Marshal(data)
-	if err != nil {
-		return diag.FromErr(err)
+func (r *assignmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var config, data assignmentResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/devices", c.HostURL), strings.NewReader(string(req_body)))
+
+	serial := data.DeviceSerial.ValueString()
+
+	user, err := resolveAssignmentUser(ctx, r.client, config)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Assigning device %q failed", serial))
+		return
 	}
 
-	_, err = c.doRequest(req)
+	err = assignDevice(ctx, r.client, serial, fmt.Sprint(user["iduser"]))
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Assigning device %q failed", serial))
+		return
 	}
 
-	d.SetId(serial)
-
-	resourceAssignmentRead(ctx, d, m)
+	data.ID = types.StringValue(serial)
+	r.refresh(ctx, &data, config, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, data.identity())...)
 }
 
-func resourceAssignmentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Client)
+func (r *assignmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data assignmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+	found := r.readAssignment(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.Diagnostics.AddWarning("Assignment no longer exists",
+			fmt.Sprintf("No %s device with serial number %q was found, removing the assignment from state", data.OS.ValueString(), data.DeviceSerial.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	serial := d.Get("device_serial").(string)
-	os := d.Get("os").(string)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, data.identity())...)
+}
 
-	options := map[string]interface{}{"os": os, "serial_numbers": [...]string{serial}}
-	data := map[string]interface{}{"operation": "list", "options": options}
-	req_body, err := json.Marshal(data)
+func (r *assignmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var config, data, state assignmentResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serial := data.DeviceSerial.ValueString()
+
+	user, err := resolveAssignmentUser(ctx, r.client, config)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Assigning device %q failed", serial))
+		return
+	}
+
+	// A different device is assigned in place, the previous one keeps its user
+	moved := !data.DeviceSerial.Equal(state.DeviceSerial) || !data.OS.Equal(state.OS)
+	if iduser := fmt.Sprint(user["iduser"]); moved || iduser != state.IDUser.ValueString() {
+		err = assignDevice(ctx, r.client, serial, iduser)
+		if err != nil {
+			resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Assigning device %q failed", serial))
+			return
+		}
+	}
+
+	data.ID = types.StringValue(serial)
+	r.refresh(ctx, &data, config, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/devices", c.HostURL), strings.NewReader(string(req_body)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, data.identity())...)
+}
+
+func (r *assignmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data assignmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.deviceOperation(ctx, "change_to_limbo", []string{data.DeviceUDID.ValueString()})
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Moving device %q to limbo failed", data.DeviceSerial.ValueString()))
+	}
+}
+
+// ImportState imports an assignment by "<os>/<serial number>" or by its identity.
+func (r *assignmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	identity := assignmentIdentityModel{}
+	if req.ID != "" {
+		os, serial, ok := strings.Cut(req.ID, "/")
+		if !ok || os == "" || serial == "" {
+			resp.Diagnostics.AddError("Unexpected import ID", fmt.Sprintf("Expected <os>/<serial number>, got %q", req.ID))
+			return
+		}
+		identity.OS = types.StringValue(os)
+		identity.DeviceSerial = types.StringValue(serial)
+	} else {
+		resp.Diagnostics.Append(req.Identity.Get(ctx, &identity)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	response, err := c.doRequest(req)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), identity.DeviceSerial)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("os"), identity.OS)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device_serial"), identity.DeviceSerial)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
+}
+
+func (data assignmentResourceModel) identity() assignmentIdentityModel {
+	return assignmentIdentityModel{OS: data.OS, DeviceSerial: data.DeviceSerial}
+}
+
+// refresh reads the assignment back after a change. The configured attributes
+// keep the value from the configuration, Mosyle may spell them differently.
+func (r *assignmentResource) refresh(ctx context.Context, data *assignmentResourceModel, config assignmentResourceModel, diags *diag.Diagnostics) {
+	planned := *data
+
+	found := r.readAssignment(ctx, data, diags)
+	if diags.HasError() {
+		return
+	}
+	if !found {
+		diags.AddError("Assignment not found", fmt.Sprintf("No %s device with serial number %q was found", planned.OS.ValueString(), planned.DeviceSerial.ValueString()))
+		return
+	}
+
+	data.OS = planned.OS
+	data.DeviceSerial = planned.DeviceSerial
+	for _, attribute := range []struct{ configured, value, plan *types.String }{
+		{&config.UserID, &data.UserID, &planned.UserID},
+		{&config.UserIdentifier, &data.UserIdentifier, &planned.UserIdentifier},
+		{&config.IDUser, &data.IDUser, &planned.IDUser},
+	} {
+		if !attribute.configured.IsNull() {
+			*attribute.value = *attribute.plan
+		}
+	}
+}
+
+// readAssignment refreshes data from Mosyle and reports whether the device exists.
+func (r *assignmentResource) readAssignment(ctx context.Context, data *assignmentResourceModel, diags *diag.Diagnostics) bool {
+	c := r.client
+
+	serial := data.DeviceSerial.ValueString()
+	os := data.OS.ValueString()
+
+	devices, err := c.listDevices(ctx, map[string]interface{}{"os": os, "serial_numbers": []string{serial}})
 	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	Assignment := flattenAssignment(response)
-	if Assignment == nil {
-		return append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "No such Assignment",
-			Detail:   "Assignment does not exist",
-		})
-	}
-	for key, val := range Assignment {
-		if err := d.Set(key, val); err != nil {
-			return append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "Failed to transfer data",
-				Detail:   err.Error(),
-			})
+		diags.AddError(err.Error(), fmt.Sprintf("Listing %s device %q failed", os, serial))
+		return false
+	}
+
+	assignment := flattenAssignment(devices, serial)
+	if assignment == nil {
+		return false
+	}
+	if assignment["os"] == nil {
+		assignment["os"] = os
+	}
+
+	// The device only knows the Mosyle user id, the identifier comes from the user
+	if iduser := assignment["iduser"].(string); iduser != "" {
+		users, err := findUsers(ctx, c, "iduser", iduser)
+		if err != nil {
+			diags.AddError(err.Error(), fmt.Sprintf("Listing user %q failed", iduser))
+			return false
+		}
+		if len(users) == 1 {
+			assignment["user_identifier"] = fmt.Sprint(users[0]["identifier"])
 		}
 	}
 
-	return diags
+	// Keep the deprecated user_id in whichever form the configuration uses
+	if user_id := data.UserID.ValueString(); user_id != "" && user_id == assignment["user_identifier"] {
+		assignment["user_id"] = assignment["user_identifier"]
+	}
+
+	if err := setAssignmentModel(data, assignment); err != nil {
+		diags.AddError("Failed to transfer data", err.Error())
+		return false
+	}
+
+	return true
 }
 
-func resourceAssignmentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	return resourceAssignmentRead(ctx, d, m)
+// setAssignmentModel copies a flattened assignment into data.
+func setAssignmentModel(data *assignmentResourceModel, assignment map[string]interface{}) error {
+	for key, value := range map[string]*types.String{
+		"os":              &data.OS,
+		"user_id":         &data.UserID,
+		"user_identifier": &data.UserIdentifier,
+		"iduser":          &data.IDUser,
+		"device_serial":   &data.DeviceSerial,
+		"device_udid":     &data.DeviceUDID,
+	} {
+		str, err := coerceString(assignment[key])
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		*value = types.StringValue(str)
+	}
+	data.ID = data.DeviceSerial
+
+	return nil
 }
 
-func resourceAssignmentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Client)
+// resolveAssignmentUser looks up the configured user, so the assignment can be
+// made and read back with the same identity whichever form the config uses.
+// The deprecated user_id may hold either an identifier or a Mosyle user id.
+func resolveAssignmentUser(ctx context.Context, c *Client, config assignmentResourceModel) (map[string]interface{}, error) {
+	for _, attribute := range []struct {
+		name    string
+		value   types.String
+		lookups []string
+	}{
+		{"user_identifier", config.UserIdentifier, []string{"identifier"}},
+		{"iduser", config.IDUser, []string{"iduser"}},
+		{"user_id", config.UserID, []string{"identifier", "iduser"}},
+	} {
+		// Computed attributes still hold the previous user during an update,
+		// only the configured one is relevant.
+		if attribute.value.IsNull() || attribute.value.IsUnknown() {
+			continue
+		}
+
+		value := attribute.value.ValueString()
+		for _, key := range attribute.lookups {
+			users, err := findUsers(ctx, c, key, value)
+			if err != nil {
+				return nil, err
+			}
+			if len(users) > 1 {
+				return nil, fmt.Errorf("%d users match %s %q", len(users), key, value)
+			}
+			if len(users) == 1 {
+				return users[0], nil
+			}
+		}
 
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+		return nil, fmt.Errorf("no user matches %s %q", attribute.name, value)
+	}
 
-	device := d.Get("device_udid").(string)
-	data := map[string]interface{}{"operation": "change_to_limbo", "devices": [...]string{device}}
+	return nil, errors.New("one of user_identifier, iduser or user_id must be set")
+}
+
+func assignDevice(ctx context.Context, c *Client, serial string, iduser string) error {
+	assign_data := map[string]string{"iduser": iduser, "serialnumber": serial}
+	data := map[string]interface{}{"operation": "assign_device_user", "assign": [...]map[string]string{assign_data}}
 	req_body, err := json.Marshal(data)
 	if err != nil {
-		return diag.FromErr(err)
+		return err
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/devices", c.HostURL), strings.NewReader(string(req_body)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/devices", c.HostURL), strings.NewReader(string(req_body)))
 	if err != nil {
-		return diag.FromErr(err)
+		return err
 	}
 
 	_, err = c.doRequest(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
 
-	return diags
+	return err
 }
 
-func flattenAssignment(response ListResponse) map[string]interface{} {
-	if len(response.Response) < 1 || len(response.Response[0].Devices) < 1 {
-		return make(map[string]interface{}, 0)
+func flattenAssignment(devices []map[string]interface{}, serial string) map[string]interface{} {
+	for _, device := range devices {
+		if strings.EqualFold(fmt.Sprint(device["serial_number"]), serial) {
+			return assignmentRecord(device)
+		}
 	}
 
+	return nil
+}
+
+// assignmentRecord describes the assignment of a single device record.
+func assignmentRecord(device map[string]interface{}) map[string]interface{} {
+	iduser, _ := coerceString(device["idusermosyle"])
+
 	oi := make(map[string]interface{})
-	oi["os"] = response.Response[0].Devices[0]["os"]
-	oi["user_id"] = response.Response[0].Devices[0]["idusermosyle"]
-	oi["device_serial"] = response.Response[0].Devices[0]["serial_number"]
-	oi["device_udid"] = response.Response[0].Devices[0]["deviceudid"]
+	oi["os"] = device["os"]
+	oi["user_id"] = iduser
+	oi["iduser"] = iduser
+	oi["user_identifier"] = ""
+	oi["device_serial"] = device["serial_number"]
+	oi["device_udid"] = device["deviceudid"]
 
 	return oi
 }
+
+// sameDeviceStateForUnknown keeps the id and UDID from state while the
+// assignment stays on the same device, they change with os or device_serial.
+type sameDeviceStateForUnknown struct{}
+
+func (m sameDeviceStateForUnknown) Description(ctx context.Context) string {
+	return "Once set, the value does not change unless os or device_serial changes"
+}
+
+func (m sameDeviceStateForUnknown) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m sameDeviceStateForUnknown) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var plan, state assignmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.OS.Equal(state.OS) && plan.DeviceSerial.Equal(state.DeviceSerial) {
+		resp.PlanValue = req.StateValue
+	}
+}
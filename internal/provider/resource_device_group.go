@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = &deviceGroupResource{}
+	_ resource.ResourceWithIdentity    = &deviceGroupResource{}
+	_ resource.ResourceWithImportState = &deviceGroupResource{}
+)
+
+func NewDeviceGroupResource() resource.Resource {
+	return &deviceGroupResource{}
+}
+
+// deviceGroupResource adopts device groups that exist in Mosyle. The API has
+// no operations to create, change or delete a device group, so groups can
+// only be imported and destroying one only removes it from state.
+type deviceGroupResource struct {
+	client *Client
+}
+
+type deviceGroupResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	DeviceNumbers types.Int64  `tfsdk:"device_numbers"`
+}
+
+type deviceGroupIdentityModel struct {
+	ID types.String `tfsdk:"id"`
+}
+
+func (r *deviceGroupResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_device_group"
+}
+
+func (r *deviceGroupResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Device group that exists in Mosyle. Device groups cannot be created through the API, import an existing group instead. Destroying the resource leaves the group in Mosyle",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Device group id",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name":           schema.StringAttribute{Computed: true, MarkdownDescription: "Device group name"},
+			"device_numbers": schema.Int64Attribute{Computed: true, MarkdownDescription: "Number of devices in the group"},
+		},
+	}
+}
+
+func (r *deviceGroupResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"id": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Device group id",
+			},
+		},
+	}
+}
+
+func (r *deviceGroupResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	c, ok := clientFromProviderData(req.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *Client, got %T", req.ProviderData))
+		return
+	}
+
+	r.client = c
+}
+
+func (r *deviceGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.AddError("Device groups cannot be created",
+		"The Mosyle API cannot create device groups. Create the group in Mosyle and import it, for example with an import block or terraform query.")
+}
+
+func (r *deviceGroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data deviceGroupResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found := r.readDeviceGroup(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.Diagnostics.AddWarning("Device group no longer exists",
+			fmt.Sprintf("Device group %q was removed outside of Terraform, removing it from state", data.ID.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, deviceGroupIdentityModel{ID: data.ID})...)
+}
+
+// Update is never called, none of the attributes can be configured.
+func (r *deviceGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data deviceGroupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *deviceGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// ImportState imports a device group by its id.
+func (r *deviceGroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughWithIdentity(ctx, path.Root("id"), path.Root("id"), req, resp)
+}
+
+// readDeviceGroup refreshes data from Mosyle and reports whether the group exists.
+func (r *deviceGroupResource) readDeviceGroup(ctx context.Context, data *deviceGroupResourceModel, diags *diag.Diagnostics) bool {
+	groups, err := r.client.listDeviceGroups(ctx, map[string]interface{}{})
+	if err != nil {
+		diags.AddError(err.Error(), "Listing device groups failed")
+		return false
+	}
+
+	for _, group := range flattenDeviceGroups(DeviceGroupListResponse{Response: ListResult{DeviceGroups: groups}}) {
+		oi := group.(map[string]interface{})
+		if oi["id"] == data.ID.ValueString() {
+			setDeviceGroupModel(data, oi)
+			return true
+		}
+	}
+
+	return false
+}
+
+// setDeviceGroupModel copies a flattened device group into data.
+func setDeviceGroupModel(data *deviceGroupResourceModel, group map[string]interface{}) {
+	id, _ := group["id"].(string)
+	name, _ := group["name"].(string)
+	data.ID = types.StringValue(id)
+	data.Name = types.StringValue(name)
+
+	data.DeviceNumbers = types.Int64Null()
+	if count, ok := group["device_numbers"].(int64); ok {
+		data.DeviceNumbers = types.Int64Value(count)
+	}
+}
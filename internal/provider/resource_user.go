@@ -7,112 +7,279 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceUser() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceUserCreate,
-		ReadContext:   resourceUserRead,
-		UpdateContext: resourceUserUpdate,
-		DeleteContext: resourceUserDelete,
-		Description:   "User data",
-		Schema: map[string]*schema.Schema{
-			"name":       &schema.Schema{Type: schema.TypeString, Required: true, Description: "User name"},
-			"identifier": &schema.Schema{Type: schema.TypeString, Required: true, Description: "User identifier, set by admin"},
-			"email":      &schema.Schema{Type: schema.TypeString, Optional: true, Description: "User email"},
-			"type":       &schema.Schema{Type: schema.TypeString, Optional: true, Default: "ENDUSER", Description: "User type, one of (ENDUSER|GROUP_ADMIN|ADMIN) default: ENDUSER"},
-			"iduser":     &schema.Schema{Type: schema.TypeString, Computed: true, Description: "User id from mosyle"},
-			"code":       &schema.Schema{Type: schema.TypeString, Computed: true, Description: "User code"},
-			"is_removed": &schema.Schema{Type: schema.TypeBool, Computed: true, Description: "User is removed"},
+var (
+	_ resource.ResourceWithConfigure      = &userResource{}
+	_ resource.ResourceWithValidateConfig = &userResource{}
+	_ resource.ResourceWithIdentity       = &userResource{}
+	_ resource.ResourceWithImportState    = &userResource{}
+)
+
+func NewUserResource() resource.Resource {
+	return &userResource{}
+}
+
+type userResource struct {
+	client *Client
+}
+
+type userResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Name       types.String `tfsdk:"name"`
+	Identifier types.String `tfsdk:"identifier"`
+	Email      types.String `tfsdk:"email"`
+	Type       types.String `tfsdk:"type"`
+	IDUser     types.String `tfsdk:"iduser"`
+	Code       types.String `tfsdk:"code"`
+	IsRemoved  types.Bool   `tfsdk:"is_removed"`
+}
+
+type userIdentityModel struct {
+	Identifier types.String `tfsdk:"identifier"`
+}
+
+func (r *userResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+	// Like the SDK resource, a changed identifier is updated in place
+	resp.ResourceBehavior.MutableIdentity = true
+}
+
+func (r *userResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	computed := []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "User data",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The ID of this resource.",
+				PlanModifiers:       computed,
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "User name",
+				Validators:          []validator.String{notEmptyValidator},
+			},
+			"identifier": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "User identifier, set by admin",
+				Validators:          []validator.String{notEmptyValidator},
+			},
+			"email": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "User email, required for admins",
+				Validators:          []validator.String{emailValidator},
+			},
+			"type": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("ENDUSER"),
+				MarkdownDescription: "User type, one of (ENDUSER|GROUP_ADMIN|ADMIN) default: ENDUSER",
+				Validators:          []validator.String{userTypeValidator},
+			},
+			"iduser":     schema.StringAttribute{Computed: true, MarkdownDescription: "User id from mosyle", PlanModifiers: computed},
+			"code":       schema.StringAttribute{Computed: true, MarkdownDescription: "User code", PlanModifiers: computed},
+			"is_removed": schema.BoolAttribute{Computed: true, MarkdownDescription: "User is removed", PlanModifiers: []planmodifier.Bool{boolplanmodifier.UseStateForUnknown()}},
 		},
 	}
 }
 
-func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Client)
+// IdentitySchema identifies users by their identifier, the list resource
+// returns it for terraform query to write import blocks with.
+func (r *userResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"identifier": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "User identifier, set by admin",
+			},
+		},
+	}
+}
 
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+func (r *userResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	c, ok := clientFromProviderData(req.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *Client, got %T", req.ProviderData))
+		return
+	}
 
-	name := d.Get("name").(string)
-	id := d.Get("identifier").(string)
-	user_type := d.Get("type").(string)
+	r.client = c
+}
 
-	data := map[string]string{"operation": "create_user", "user_id": id, "type": user_type, "name": name}
-	req_body, err := json.Marshal(data)
-	if err != nil {
-		return diag.FromErr(err)
+// ValidateConfig checks the rules that span several mosyle_user attributes.
+func (r *userResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data userResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/users", c.HostURL), strings.NewReader(string(req_body)))
-	if err != nil {
-		return diag.FromErr(err)
+
+	if data.Type.IsUnknown() || data.Email.IsUnknown() || data.Type.IsNull() {
+		return
 	}
 
-	_, err = c.doRequest(req)
-	if err != nil {
-		return diag.FromErr(err)
+	user_type := data.Type.ValueString()
+	if user_type != "ENDUSER" && data.Email.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(path.Root("email"), "Missing email",
+			fmt.Sprintf("email is required for users of type %s, they sign in to Mosyle with it", user_type))
+	}
+}
+
+func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data userResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.saveUser(ctx, "create_user", data); err != nil {
+		resp.Diagnostics.AddError(err.Error(), fmt.Sprintf("Creating user %q failed", data.Identifier.ValueString()))
+		return
+	}
+
+	data.ID = data.Identifier
+	if found := r.readUser(ctx, &data, &resp.Diagnostics); !found && !resp.Diagnostics.HasError() {
+		resp.Diagnostics.AddError("User not found after creation", fmt.Sprintf("User %q was created, but cannot be listed", data.ID.ValueString()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.SetId(id)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, userIdentityModel{Identifier: data.Identifier})...)
+}
+
+func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data userResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	resourceUserRead(ctx, d, m)
+	found := r.readUser(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found || data.IsRemoved.ValueBool() {
+		resp.Diagnostics.AddWarning("User no longer exists",
+			fmt.Sprintf("User %q was removed outside of Terraform, removing it from state", data.ID.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, userIdentityModel{Identifier: data.Identifier})...)
 }
 
-func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Client)
+func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data userResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+	// Like the SDK resource, changes are not sent to Mosyle, the next refresh
+	// reads the user back
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, userIdentityModel{Identifier: data.Identifier})...)
+}
+
+func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
 
-	req_body, err := json.Marshal(ListUserBody{
-		Operation: "list_users",
-		Options:   map[string][]string{"identifiers": []string{d.Id()}},
-	})
+// ImportState imports a user by its identifier.
+func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughWithIdentity(ctx, path.Root("id"), path.Root("identifier"), req, resp)
+}
+
+// saveUser sends the configured attributes of a user with the given operation.
+func (r *userResource) saveUser(ctx context.Context, operation string, data userResourceModel) error {
+	c := r.client
+
+	body := map[string]string{
+		"operation": operation,
+		"user_id":   data.Identifier.ValueString(),
+		"type":      data.Type.ValueString(),
+		"name":      data.Name.ValueString(),
+	}
+	if email := data.Email.ValueString(); email != "" {
+		body["email"] = email
+	}
+	req_body, err := json.Marshal(body)
 	if err != nil {
-		return diag.FromErr(err)
+		return err
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/users", c.HostURL), strings.NewReader(string(req_body)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/users", c.HostURL), strings.NewReader(string(req_body)))
 	if err != nil {
-		return diag.FromErr(err)
+		return err
 	}
 
-	response, err := c.doRequest(req)
+	_, err = c.doRequest(req)
+
+	return err
+}
+
+// readUser refreshes data from Mosyle and reports whether the user exists.
+func (r *userResource) readUser(ctx context.Context, data *userResourceModel, diags *diag.Diagnostics) bool {
+	id := data.ID.ValueString()
+	records, err := r.client.listUsers(ctx, map[string]interface{}{"identifiers": []string{id}})
 	if err != nil {
-		return diag.FromErr(err)
+		diags.AddError(err.Error(), fmt.Sprintf("Listing user %q failed", id))
+		return false
 	}
 
-	users := flattenUsers(response)
+	users := flattenUsers(records)
 	if len(users) < 1 {
-		return append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "No such user",
-			Detail:   "User does not exist",
-		})
-	}
-	for key, val := range users[0] {
-		if err := d.Set(key, val); err != nil {
-			return append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "Failed to transfer data",
-				Detail:   err.Error(),
-			})
-		}
+		return false
 	}
 
-	return diags
-}
+	if err := setUserModel(data, users[0]); err != nil {
+		diags.AddError("Failed to transfer data", err.Error())
+		return false
+	}
 
-func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	return resourceUserRead(ctx, d, m)
+	return true
 }
 
-func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+// setUserModel copies a flattened user record into data.
+func setUserModel(data *userResourceModel, user map[string]interface{}) error {
+	for key, value := range map[string]*types.String{
+		"name":       &data.Name,
+		"identifier": &data.Identifier,
+		"email":      &data.Email,
+		"type":       &data.Type,
+		"iduser":     &data.IDUser,
+		"code":       &data.Code,
+	} {
+		str, err := coerceString(user[key])
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		if str == "" && key == "email" {
+			*value = types.StringNull()
+			continue
+		}
+		*value = types.StringValue(str)
+	}
+
+	removed, err := coerceBool(user["is_removed"])
+	if err != nil {
+		return fmt.Errorf("is_removed: %s", err)
+	}
+	data.IsRemoved = types.BoolValue(removed)
 
-	return diags
+	return nil
 }
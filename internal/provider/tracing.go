@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of API requests.
+const tracerName = "github.com/smillerdev/terraform-provider-mosyle"
+
+// StartTracing exports the spans of API requests with OTLP when
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set.
+// The exporter reads the other OTEL_EXPORTER_OTLP_* variables itself, the
+// protocol is http/protobuf unless grpc is asked for. Without an endpoint
+// nothing is exported. The returned function flushes the remaining spans.
+func StartTracing(ctx context.Context, service string, version string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return noop, nil
+	}
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return noop, nil
+	}
+
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	var exporter *otlptrace.Exporter
+	var err error
+	switch protocol {
+	case "", "http/protobuf":
+		exporter, err = otlptracehttp.New(ctx)
+	case "grpc":
+		exporter, err = otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, expected grpc or http/protobuf", protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", service), attribute.String("service.version", version)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// operationKey is the context key that marks the requests of an operation,
+// its value is the name of the operation.
+type operationKey struct{}
+
+// startOperation starts the span of an API operation. The requests sent with
+// the returned context are its children.
+func startOperation(ctx context.Context, endpoint string, operation string) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "mosyle "+operation, trace.WithAttributes(
+		attribute.String("mosyle.operation", operation),
+		attribute.String("mosyle.endpoint", endpoint),
+	))
+
+	return context.WithValue(ctx, operationKey{}, operation), span
+}
+
+// startRequestOperation starts the span of an operation that is a single
+// request, unless the request already belongs to an operation.
+func startRequestOperation(req *http.Request) (*http.Request, trace.Span) {
+	if _, ok := req.Context().Value(operationKey{}).(string); ok {
+		return req, nil
+	}
+
+	operation, _ := requestOperation(req)
+	endpoint := path.Base(req.URL.Path)
+	if operation == "" {
+		operation = endpoint
+	}
+
+	ctx, span := startOperation(req.Context(), endpoint, operation)
+
+	return req.WithContext(ctx), span
+}
+
+// endOperation records the error of an operation and ends its span.
+func endOperation(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracingTransport creates a span for every API request, as a child of the
+// span of its operation. The operation and page are read from the request
+// body.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation, page := requestOperation(req)
+	endpoint := path.Base(req.URL.Path)
+	if operation == "" {
+		operation = endpoint
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(req.Context(), req.Method+" "+endpoint, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("mosyle.operation", operation),
+		attribute.String("mosyle.endpoint", endpoint),
+		attribute.String("http.request.method", req.Method),
+	)
+	if page > 0 {
+		span.SetAttributes(attribute.Int("mosyle.page", page))
+	}
+
+	response, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return response, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		span.SetStatus(codes.Error, response.Status)
+	}
+
+	return response, nil
+}
+
+// requestOperation reads the operation and page from the JSON body of an API
+// request, without consuming the body.
+func requestOperation(req *http.Request) (string, int) {
+	if req.GetBody == nil {
+		return "", 0
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", 0
+	}
+	defer body.Close()
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", 0
+	}
+
+	request_obj := struct {
+		Operation string `json:"operation"`
+		Options   struct {
+			Page int `json:"page"`
+		} `json:"options"`
+	}{}
+	json.Unmarshal(b, &request_obj)
+
+	return request_obj.Operation, request_obj.Options.Page
+}
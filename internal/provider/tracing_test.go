@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body ListPostBody
+		json.NewDecoder(r.Body).Decode(&body)
+
+		page := body.Options["page"]
+		fmt.Fprintf(w, `{"status":"OK","response":[{"users":[{"iduser":"%v","identifier":"user%v"}],"rows":2}]}`, page, page)
+	}))
+	defer server.Close()
+
+	c, _ := MosyleClient("dev", nil, nil, nil)
+	c.HostURL = server.URL
+
+	// The context of a Terraform operation, the API operation is its child
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "read")
+	if _, err := c.listUsers(ctx, map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+
+	operations := spans["mosyle list_users"]
+	if len(operations) != 1 {
+		t.Fatalf("expected a single span for the operation, got %d", len(operations))
+	}
+	operation := operations[0]
+	if operation.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected the operation span to be a child of the caller's span")
+	}
+	if pages := spanAttributes(operation)["mosyle.pages"]; pages != attribute.IntValue(2) {
+		t.Errorf("expected mosyle.pages to be 2, got %s", pages.Emit())
+	}
+
+	requests := spans["POST users"]
+	if len(requests) != 2 {
+		t.Fatalf("expected a span for each of the 2 pages, got %d", len(requests))
+	}
+	for i, span := range requests {
+		if span.Parent().SpanID() != operation.SpanContext().SpanID() {
+			t.Errorf("span %d: expected the page to be a child of the operation span", i)
+		}
+
+		attributes := spanAttributes(span)
+		for key, expected := range map[attribute.Key]attribute.Value{
+			"mosyle.operation":          attribute.StringValue("list_users"),
+			"mosyle.endpoint":           attribute.StringValue("users"),
+			"mosyle.page":               attribute.IntValue(i + 1),
+			"http.response.status_code": attribute.IntValue(http.StatusOK),
+		} {
+			if attributes[key] != expected {
+				t.Errorf("span %d: expected %s to be %s, got %s", i, key, expected.Emit(), attributes[key].Emit())
+			}
+		}
+	}
+}
+
+func TestLoginSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Authorization", "Bearer token")
+	}))
+	defer server.Close()
+
+	username, password, token := "admin@example.com", "secret", "token"
+	c, _ := MosyleClient("dev", &username, &password, &token)
+	c.HostURL = server.URL
+
+	if _, _, err := c.login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	operation, ok := spans["mosyle login"]
+	if !ok {
+		t.Fatalf("expected a span for the login operation, got %v", spans)
+	}
+	request, ok := spans["POST login"]
+	if !ok {
+		t.Fatalf("expected a span for the login request, got %v", spans)
+	}
+	if request.Parent().SpanID() != operation.SpanContext().SpanID() {
+		t.Errorf("expected the request to be a child of the login operation span")
+	}
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	return attributes
+}
+
+func TestStartTracingDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	previous := otel.GetTracerProvider()
+
+	shutdown, err := StartTracing(context.Background(), "test", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if otel.GetTracerProvider() != previous {
+		t.Errorf("expected the tracer provider to be left alone without an endpoint")
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	if _, err := StartTracing(context.Background(), "test", "dev"); err == nil {
+		t.Errorf("expected an unsupported protocol to be rejected")
+	}
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig configures how a Client connects to Mosyle.
+type TransportConfig struct {
+	// ProxyURL is used instead of the proxy from HTTPS_PROXY and friends.
+	ProxyURL string
+	// CABundleFile contains PEM certificates that are trusted on top of the
+	// system certificates, for proxies that intercept TLS.
+	CABundleFile string
+	// InsecureSkipVerify disables certificate verification, only meant for
+	// emulators of the Mosyle API.
+	InsecureSkipVerify bool
+}
+
+// newTransport builds a transport for the configuration. Idle connections are
+// kept for every concurrent list operation, so paginated reads reuse them.
+// Like http.DefaultTransport it asks for gzip and decompresses responses.
+// Problems are reported on the provider attribute they come from.
+func newTransport(config TransportConfig) (*http.Transport, []configProblem) {
+	problems := make([]configProblem, 0)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 2 * maxConcurrentQueries
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.ProxyURL != "" {
+		proxy, err := url.Parse(config.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			problems = append(problems, configProblem{"proxy_url", "Invalid proxy URL",
+				fmt.Sprintf("%q is not a URL such as http://proxy.example.com:3128", config.ProxyURL)})
+		} else {
+			transport.Proxy = http.ProxyURL(proxy)
+		}
+	}
+
+	if config.CABundleFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		b, err := os.ReadFile(config.CABundleFile)
+		if err != nil {
+			problems = append(problems, configProblem{"ca_bundle_file", "Failed to read CA bundle", err.Error()})
+		} else if !pool.AppendCertsFromPEM(b) {
+			problems = append(problems, configProblem{"ca_bundle_file", "Invalid CA bundle",
+				fmt.Sprintf("%s does not contain any PEM encoded certificates", config.CABundleFile)})
+		} else {
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	}
+
+	return transport, problems
+}
+
+// configureTransport replaces the HTTP client of c with one that uses a
+// transport for the configuration, with a span for every request.
+func (c *Client) configureTransport(config TransportConfig) []configProblem {
+	transport, problems := newTransport(config)
+	c.HTTPClient = &http.Client{Transport: &tracingTransport{next: transport}}
+
+	return problems
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransportCABundle(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK","response":[{"users":[],"rows":0}]}`))
+	}))
+	// The rejected handshake is expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	token := "token"
+	c, _ := MosyleClient("dev", nil, nil, &token)
+	c.HostURL = server.URL
+
+	if _, err := c.listUsers(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatalf("expected the test certificate to be rejected without a CA bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	if problems := c.configureTransport(TransportConfig{CABundleFile: bundle}); len(problems) != 0 {
+		t.Fatalf("unexpected problems %+v", problems)
+	}
+	if _, err := c.listUsers(context.Background(), map[string]interface{}{}); err != nil {
+		t.Errorf("expected the CA bundle to be trusted, got %s", err)
+	}
+
+	if problems := c.configureTransport(TransportConfig{InsecureSkipVerify: true}); len(problems) != 0 {
+		t.Fatalf("unexpected problems %+v", problems)
+	}
+	if _, err := c.listUsers(context.Background(), map[string]interface{}{}); err != nil {
+		t.Errorf("expected verification to be skipped, got %s", err)
+	}
+}
+
+func TestTransportProblems(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("no certificates"), 0o600)
+
+	for name, tc := range map[string]struct {
+		config    TransportConfig
+		attribute string
+	}{
+		"proxy":          {TransportConfig{ProxyURL: "proxy.example.com"}, "proxy_url"},
+		"missing bundle": {TransportConfig{CABundleFile: filepath.Join(t.TempDir(), "missing.pem")}, "ca_bundle_file"},
+		"empty bundle":   {TransportConfig{CABundleFile: empty}, "ca_bundle_file"},
+	} {
+		_, problems := newTransport(tc.config)
+		if len(problems) != 1 || problems[0].attribute != tc.attribute {
+			t.Errorf("%s: expected a problem with %s, got %+v", name, tc.attribute, problems)
+		}
+	}
+}
+
+func TestTransportProxyAndGzip(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.Host == "mosyle.invalid"
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.Write([]byte(`{"status":"OK","response":[{"users":[],"rows":0}]}`))
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"status":"OK","response":[{"users":[{"iduser":"1"}],"rows":1}]}`))
+		gz.Close()
+	}))
+	defer proxy.Close()
+
+	token := "token"
+	c, _ := MosyleClient("dev", nil, nil, &token)
+	c.HostURL = "http://mosyle.invalid"
+	c.configureTransport(TransportConfig{ProxyURL: proxy.URL})
+
+	users, err := c.listUsers(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proxied {
+		t.Errorf("expected the request to go through the proxy")
+	}
+	if len(users) != 1 {
+		t.Errorf("expected the gzip response to be decoded, got %v", users)
+	}
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// deviceOSes lists the operating systems accepted by the Mosyle "list" operation.
+var deviceOSes = []string{"mac", "ios", "tvos", "visionos"}
+
+// deviceOSList lists deviceOSes and any extra values as "(mac|ios|...)" for
+// descriptions, so they stay in line with the accepted values.
+func deviceOSList(extra ...string) string {
+	return "(" + strings.Join(append(append([]string{}, deviceOSes...), extra...), "|") + ")"
+}
+
+// userTypes lists the types a Mosyle user can have.
+var userTypes = []string{"ENDUSER", "GROUP_ADMIN", "ADMIN"}
+
+// serialNumberPattern matches Apple serial numbers, 8 to 14 letters and digits.
+var serialNumberPattern = regexp.MustCompile(`^[A-Za-z0-9]{8,14}$`)
+
+// emailPattern is deliberately loose, it only catches obvious typos.
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+var (
+	validateOS           = validation.ToDiagFunc(validation.StringInSlice(deviceOSes, false))
+	validateUserType     = validation.ToDiagFunc(validation.StringInSlice(userTypes, false))
+	validateSerialNumber = validation.ToDiagFunc(validation.StringMatch(serialNumberPattern, "must be an Apple serial number of 8 to 14 letters and digits"))
+	validateEmail        = validation.ToDiagFunc(validation.StringMatch(emailPattern, "must be an email address"))
+	validateNotEmpty     = validation.ToDiagFunc(validation.StringIsNotWhiteSpace)
+)
+
+// The same checks for resources and data sources built on the plugin framework.
+var (
+	osValidator           = stringvalidator.OneOf(deviceOSes...)
+	userTypeValidator     = stringvalidator.OneOf(userTypes...)
+	serialNumberValidator = stringvalidator.RegexMatches(serialNumberPattern, "must be an Apple serial number of 8 to 14 letters and digits")
+	emailValidator        = stringvalidator.RegexMatches(emailPattern, "must be an email address")
+	notEmptyValidator     = stringvalidator.RegexMatches(regexp.MustCompile(`\S`), "must not be empty or consist only of white-space characters")
+)
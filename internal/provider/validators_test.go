@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestSerialNumberPattern(t *testing.T) {
+	for serial, valid := range map[string]bool{
+		"C02XK0AAJGH5": true,
+		"DMPXL2Y3KXKN": true,
+		"jayt56efsr23": true,
+		"C02-XK0AAJ":   false,
+		"ABC123":       false,
+		"":             false,
+	} {
+		if got := serialNumberPattern.MatchString(serial); got != valid {
+			t.Errorf("%q: expected %v, got %v", serial, valid, got)
+		}
+	}
+}
+
+func TestEmailPattern(t *testing.T) {
+	for email, valid := range map[string]bool{
+		"h.kar@example.com": true,
+		"h.kar@example":     false,
+		"h kar@example.com": false,
+		"example.com":       false,
+	} {
+		if got := emailPattern.MatchString(email); got != valid {
+			t.Errorf("%q: expected %v, got %v", email, valid, got)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"log"
+	"os"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 	"github.com/smillerdev/terraform-provider-mosyle/internal/provider"
 )
 
@@ -27,19 +31,72 @@ var (
 )
 
 func main() {
+	// Spans of API requests are only exported when OTEL_EXPORTER_OTLP_ENDPOINT
+	// is set, see provider.StartTracing.
+	shutdown, err := provider.StartTracing(context.Background(), "terraform-provider-mosyle", version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		err := export(os.Args[2:])
+		shutdown(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	var debugMode bool
 
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := &plugin.ServeOpts{
-		Debug: debugMode,
+	// The provider is served from both the SDK and the plugin framework while
+	// resources are moved over to the framework.
+	server, err := provider.MuxServer(context.Background(), version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var serveOpts []tf5server.ServeOpt
+	if debugMode {
+		serveOpts = append(serveOpts, tf5server.WithManagedDebug())
+	}
+
+	err = tf5server.Serve("registry.terraform.io/smillerdev/mosyle", server, serveOpts...)
+	shutdown(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// export writes the existing users, groups and assignments of a tenant as
+// configuration with import blocks, see provider.Export.
+func export(args []string) error {
+	var dir string
+
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.StringVar(&dir, "dir", ".", "directory to write the .tf files to")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export [-dir DIR]\n\nWrites users.tf, assignments.tf, user_groups.tf and device_groups.tf for the tenant of MOSYLE_USERNAME, MOSYLE_PASSWORD and MOSYLE_TOKEN.\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	c, err := provider.MosyleClientFromEnv(version)
+	if err != nil {
+		return err
+	}
 
-		// TODO: update this string with the full name of your provider as used in your configs
-		ProviderAddr: "registry.terraform.io/smillerdev/mosyle",
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
 
-		ProviderFunc: provider.New(version),
+	warnings, err := provider.Export(c, dir)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
 	}
 
-	plugin.Serve(opts)
+	return err
 }